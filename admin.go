@@ -0,0 +1,62 @@
+package logger_http
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/gol4ng/logger"
+)
+
+// MaxAdminBodySize is the maximum size of the settings accepted by AdminHandler
+const MaxAdminBodySize = 1 << 20
+
+// AdminHandler will expose the live configuration as json
+// GET returns the current settings, PUT replaces them and logs the change with log
+// concurrent PUT are serialized, each logged change holds the settings it actually replaced
+// the handler does not authenticate its callers: anyone reaching it can enable dumps and debug logging,
+// it must be mounted behind an authentication middleware or on an internal-only listener
+// eg: adminMux.Handle("/admin/logger-http", logger_http.AdminHandler(live, myLogger))
+func AdminHandler(live *LiveConfig, log logger.LoggerInterface) http.Handler {
+	return http.HandlerFunc(func(writer http.ResponseWriter, req *http.Request) {
+		switch req.Method {
+		case http.MethodGet:
+			writeSettings(writer, http.StatusOK, live.Load())
+		case http.MethodPut:
+			settings := LiveSettings{}
+			decoder := json.NewDecoder(http.MaxBytesReader(writer, req.Body, MaxAdminBodySize))
+			decoder.DisallowUnknownFields()
+			if err := decoder.Decode(&settings); err != nil {
+				http.Error(writer, err.Error(), http.StatusBadRequest)
+				return
+			}
+			previous, err := live.Swap(settings)
+			if err != nil {
+				http.Error(writer, err.Error(), http.StatusUnprocessableEntity)
+				return
+			}
+			if log != nil {
+				previousJSON, _ := json.Marshal(previous)
+				settingsJSON, _ := json.Marshal(settings)
+				log.Notice("logger-http live configuration updated",
+					logger.String("http_client_ip", clientIP(req)),
+					logger.String("http_user_agent", SanitizeString(req.UserAgent(), 0)),
+					logger.String("live_config_previous", string(previousJSON)),
+					logger.String("live_config", string(settingsJSON)),
+				)
+			}
+			writeSettings(writer, http.StatusOK, settings)
+		default:
+			writer.Header().Set("Allow", "GET, PUT")
+			http.Error(writer, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		}
+	})
+}
+
+func writeSettings(writer http.ResponseWriter, statusCode int, settings LiveSettings) {
+	if settings.Routes == nil {
+		settings.Routes = []LiveRoute{}
+	}
+	writer.Header().Set("Content-Type", "application/json")
+	writer.WriteHeader(statusCode)
+	json.NewEncoder(writer).Encode(settings)
+}
@@ -0,0 +1,111 @@
+package logger_http_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/gol4ng/logger"
+	testing_logger "github.com/gol4ng/logger/testing"
+	"github.com/stretchr/testify/assert"
+
+	logger_http "github.com/gol4ng/logger-http"
+)
+
+func TestAdminHandler(t *testing.T) {
+	live, err := logger_http.NewLiveConfig(logger_http.LiveSettings{})
+	assert.Nil(t, err)
+	myLogger, store := testing_logger.NewLogger()
+	handler := logger_http.AdminHandler(live, myLogger)
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/admin", nil))
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, "application/json", recorder.Header().Get("Content-Type"))
+	assert.JSONEq(t, `{"routes":[],"dump":{}}`, recorder.Body.String())
+
+	settings := `{"routes":[{"path_prefix":"/api","levels":[{"from":200,"to":299,"level":"debug"}],"sample_rate":0.5}],"dump":{"hosts":["my-host"]}}`
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodPut, "/admin", strings.NewReader(settings)))
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.JSONEq(t, settings, recorder.Body.String())
+	assert.Equal(t, "/api", live.Load().Routes[0].PathPrefix)
+
+	entries := store.GetEntries()
+	assert.Len(t, entries, 1)
+	assert.Equal(t, logger.NoticeLevel, entries[0].Level)
+	assert.Equal(t, "logger-http live configuration updated", entries[0].Message)
+	assert.Equal(t, `{"routes":null,"dump":{}}`, (*entries[0].Context)["live_config_previous"].Value)
+	assert.JSONEq(t, settings, (*entries[0].Context)["live_config"].Value.(string))
+	assert.Equal(t, "192.0.2.1", (*entries[0].Context)["http_client_ip"].Value)
+
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/admin", nil))
+	assert.JSONEq(t, settings, recorder.Body.String())
+}
+
+func TestAdminHandler_ConcurrentPut(t *testing.T) {
+	live, err := logger_http.NewLiveConfig(logger_http.LiveSettings{})
+	assert.Nil(t, err)
+	myLogger, store := testing_logger.NewLogger()
+	handler := logger_http.AdminHandler(live, myLogger)
+
+	const count = 20
+	wg := sync.WaitGroup{}
+	for i := 0; i < count; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodPut, "/admin", strings.NewReader(`{"dump":{"hosts":["host-`+strconv.Itoa(i)+`"]}}`)))
+			assert.Equal(t, http.StatusOK, recorder.Code)
+		}(i)
+	}
+	wg.Wait()
+
+	// every update replaced a distinct settings: the logged changes form a single chain ending with the current settings
+	replaced := map[string]bool{}
+	for _, entry := range store.GetEntries() {
+		previous := (*entry.Context)["live_config_previous"].Value.(string)
+		assert.False(t, replaced[previous], "settings %s replaced twice", previous)
+		replaced[previous] = true
+	}
+	assert.Len(t, replaced, count)
+	current, err := json.Marshal(live.Load())
+	assert.Nil(t, err)
+	assert.False(t, replaced[string(current)])
+}
+
+func TestAdminHandler_Invalid(t *testing.T) {
+	live, err := logger_http.NewLiveConfig(logger_http.LiveSettings{})
+	assert.Nil(t, err)
+	myLogger, store := testing_logger.NewLogger()
+	handler := logger_http.AdminHandler(live, myLogger)
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodPut, "/admin", strings.NewReader(`{"unknown":true}`)))
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), `unknown field "unknown"`)
+
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodPut, "/admin", strings.NewReader(`{"routes":[{"path_prefix":"api"}]}`)))
+	assert.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+	assert.Equal(t, "routes[0]: path_prefix: \"api\" must start with /\n", recorder.Body.String())
+
+	recorder = httptest.NewRecorder()
+	tooLarge := `{"routes":[],"dump":{"hosts":["` + strings.Repeat("a", logger_http.MaxAdminBodySize) + `"]}}`
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodPut, "/admin", strings.NewReader(tooLarge)))
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "request body too large")
+
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodDelete, "/admin", nil))
+	assert.Equal(t, http.StatusMethodNotAllowed, recorder.Code)
+	assert.Equal(t, "GET, PUT", recorder.Header().Get("Allow"))
+
+	assert.Len(t, store.GetEntries(), 0)
+}
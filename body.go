@@ -0,0 +1,47 @@
+package logger_http
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+)
+
+// PeekBody will read up to limit bytes of the body
+// it returns the read bytes and a body that replays them before the rest of the original body
+func PeekBody(body io.ReadCloser, limit int) ([]byte, io.ReadCloser) {
+	if body == nil || limit <= 0 {
+		return nil, body
+	}
+	peeked, err := ioutil.ReadAll(io.LimitReader(body, int64(limit)))
+	if err != nil {
+		return peeked, &replayBody{Reader: io.MultiReader(bytes.NewReader(peeked), errReader{err: err}), Closer: body}
+	}
+	return peeked, &replayBody{Reader: io.MultiReader(bytes.NewReader(peeked), body), Closer: body}
+}
+
+// GetBodyPrefix will read up to limit bytes of a replayable request body without consuming it
+func GetBodyPrefix(getBody func() (io.ReadCloser, error), limit int) []byte {
+	if getBody == nil || limit <= 0 {
+		return nil
+	}
+	body, err := getBody()
+	if err != nil || body == nil {
+		return nil
+	}
+	defer body.Close()
+	peeked, _ := ioutil.ReadAll(io.LimitReader(body, int64(limit)))
+	return peeked
+}
+
+type replayBody struct {
+	io.Reader
+	io.Closer
+}
+
+type errReader struct {
+	err error
+}
+
+func (r errReader) Read([]byte) (int, error) {
+	return 0, r.err
+}
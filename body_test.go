@@ -0,0 +1,43 @@
+package logger_http_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	logger_http "github.com/gol4ng/logger-http"
+)
+
+func TestPeekBody(t *testing.T) {
+	peeked, body := logger_http.PeekBody(ioutil.NopCloser(strings.NewReader("my body content")), 7)
+
+	assert.Equal(t, "my body", string(peeked))
+	content, err := ioutil.ReadAll(body)
+	assert.Nil(t, err)
+	assert.Equal(t, "my body content", string(content))
+}
+
+func TestPeekBody_Disabled(t *testing.T) {
+	original := ioutil.NopCloser(strings.NewReader("my body content"))
+	peeked, body := logger_http.PeekBody(original, 0)
+
+	assert.Nil(t, peeked)
+	assert.Equal(t, original, body)
+}
+
+func TestGetBodyPrefix(t *testing.T) {
+	getBody := func() (io.ReadCloser, error) {
+		return ioutil.NopCloser(bytes.NewReader([]byte("my body content"))), nil
+	}
+
+	assert.Equal(t, "my body", string(logger_http.GetBodyPrefix(getBody, 7)))
+	assert.Nil(t, logger_http.GetBodyPrefix(nil, 7))
+	assert.Nil(t, logger_http.GetBodyPrefix(func() (io.ReadCloser, error) {
+		return nil, errors.New("my get body error")
+	}, 7))
+}
@@ -0,0 +1,84 @@
+package logger_http
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/gol4ng/logger"
+)
+
+// DefaultBudgetHeader is the header carrying the remaining time budget in milliseconds
+const DefaultBudgetHeader = "X-Request-Budget"
+
+// DefaultMaxBudget caps the budget received with the budget header
+const DefaultMaxBudget = 5 * time.Minute
+
+// WithBudgetHeader customizes the header used by the deadline middleware and tripperware, DefaultBudgetHeader by default
+func WithBudgetHeader(headerName string) Option {
+	return func(o *Options) {
+		o.BudgetHeader = headerName
+	}
+}
+
+// RemainingBudget will return the time left before the go-context deadline, false when there is no deadline
+func RemainingBudget(ctx context.Context, now time.Time) (time.Duration, bool) {
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		return 0, false
+	}
+	return deadline.Sub(now), true
+}
+
+// WithMaxBudget customizes the cap of the budget received with the budget header, DefaultMaxBudget by default
+func WithMaxBudget(maxBudget time.Duration) Option {
+	return func(o *Options) {
+		o.MaxBudget = maxBudget
+	}
+}
+
+// FormatBudget will format the budget as a number of milliseconds
+// a budget under 1ms is rounded up to 1 so it still carries a deadline, an exhausted budget is formatted as 0
+func FormatBudget(budget time.Duration) string {
+	if budget <= 0 {
+		return "0"
+	}
+	milliseconds := int64(budget / time.Millisecond)
+	if budget%time.Millisecond != 0 {
+		milliseconds++
+	}
+	return strconv.FormatInt(milliseconds, 10)
+}
+
+// ParseBudget will parse a positive number of milliseconds and clamp it to maxBudget
+// 0 is an exhausted budget, a maxBudget lower or equal to 0 only prevents the duration overflow
+func ParseBudget(value string, maxBudget time.Duration) (time.Duration, error) {
+	milliseconds, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return 0, err
+	}
+	if milliseconds < 0 {
+		return 0, fmt.Errorf("budget %d must not be negative", milliseconds)
+	}
+	maxMilliseconds := int64(math.MaxInt64 / time.Millisecond)
+	if maxBudget > 0 {
+		maxMilliseconds = int64(maxBudget / time.Millisecond)
+	}
+	if milliseconds > maxMilliseconds {
+		milliseconds = maxMilliseconds
+	}
+	return time.Duration(milliseconds) * time.Millisecond, nil
+}
+
+// FeedBudgetContext will add the remaining budget and the deadline exceeded flag at completion to the logger context
+func FeedBudgetContext(loggerContext *logger.Context, ctx context.Context, now time.Time) *logger.Context {
+	remaining, ok := RemainingBudget(ctx, now)
+	if !ok {
+		return loggerContext
+	}
+	return loggerContext.
+		Add("http_remaining_budget", remaining.Seconds()).
+		Add("http_deadline_exceeded", remaining <= 0 || ctx.Err() == context.DeadlineExceeded)
+}
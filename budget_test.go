@@ -0,0 +1,74 @@
+package logger_http_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/gol4ng/logger"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/gol4ng/logger-http"
+)
+
+func TestFormatBudget(t *testing.T) {
+	assert.Equal(t, "1500", logger_http.FormatBudget(1500*time.Millisecond))
+	assert.Equal(t, "1500", logger_http.FormatBudget(1500*time.Millisecond-time.Microsecond))
+	assert.Equal(t, "1", logger_http.FormatBudget(time.Nanosecond))
+	assert.Equal(t, "0", logger_http.FormatBudget(0))
+	assert.Equal(t, "0", logger_http.FormatBudget(-time.Second))
+}
+
+func TestParseBudget(t *testing.T) {
+	budget, err := logger_http.ParseBudget("1500", time.Minute)
+	assert.Nil(t, err)
+	assert.Equal(t, 1500*time.Millisecond, budget)
+
+	budget, err = logger_http.ParseBudget("3600000", time.Minute)
+	assert.Nil(t, err)
+	assert.Equal(t, time.Minute, budget)
+
+	budget, err = logger_http.ParseBudget("9223372036854775807", 0)
+	assert.Nil(t, err)
+	assert.True(t, budget > 0)
+
+	budget, err = logger_http.ParseBudget("0", time.Minute)
+	assert.Nil(t, err)
+	assert.Equal(t, time.Duration(0), budget)
+
+	_, err = logger_http.ParseBudget("-1", time.Minute)
+	assert.EqualError(t, err, "budget -1 must not be negative")
+
+	_, err = logger_http.ParseBudget("", time.Minute)
+	assert.Error(t, err)
+}
+
+func TestRemainingBudget(t *testing.T) {
+	now := time.Date(2019, 12, 13, 17, 1, 13, 0, time.UTC)
+
+	_, ok := logger_http.RemainingBudget(context.Background(), now)
+	assert.False(t, ok)
+
+	ctx, cancel := context.WithDeadline(context.Background(), now.Add(time.Second))
+	defer cancel()
+	remaining, ok := logger_http.RemainingBudget(ctx, now)
+	assert.True(t, ok)
+	assert.Equal(t, time.Second, remaining)
+}
+
+func TestFeedBudgetContext(t *testing.T) {
+	now := time.Now()
+
+	loggerContext := logger_http.FeedBudgetContext(logger.NewContext(), context.Background(), now)
+	assert.Len(t, *loggerContext, 0)
+
+	ctx, cancel := context.WithDeadline(context.Background(), now.Add(time.Hour))
+	defer cancel()
+	loggerContext = logger_http.FeedBudgetContext(logger.NewContext(), ctx, now)
+	assert.Equal(t, time.Hour.Seconds(), (*loggerContext)["http_remaining_budget"].Value)
+	assert.Equal(t, false, (*loggerContext)["http_deadline_exceeded"].Value)
+
+	loggerContext = logger_http.FeedBudgetContext(logger.NewContext(), ctx, now.Add(2*time.Hour))
+	assert.Equal(t, -time.Hour.Seconds(), (*loggerContext)["http_remaining_budget"].Value)
+	assert.Equal(t, true, (*loggerContext)["http_deadline_exceeded"].Value)
+}
@@ -0,0 +1,297 @@
+// Package cassette stores recorded http interactions used by tripperware.Cassette
+package cassette
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// Mode tells tripperware.Cassette to record or to replay interactions
+type Mode int
+
+const (
+	// ModeReplay serves recorded interactions without network access
+	ModeReplay Mode = iota
+	// ModeRecord forwards requests and saves every interaction
+	ModeRecord
+)
+
+// ErrNotRecorded is matched by errors.Is on every MissError
+var ErrNotRecorded = errors.New("cassette: interaction not recorded")
+
+// Interaction is a recorded request/response pair
+type Interaction struct {
+	Key      string   `json:"key"`
+	Request  Request  `json:"request"`
+	Response Response `json:"response"`
+}
+
+// Request is the recorded request, the url and headers are stored redacted
+type Request struct {
+	Method string      `json:"method"`
+	URL    string      `json:"url"`
+	Header http.Header `json:"header,omitempty"`
+	Body   Body        `json:"body,omitempty"`
+}
+
+// Response is the recorded response, the headers are stored redacted
+type Response struct {
+	StatusCode int         `json:"status_code"`
+	Status     string      `json:"status"`
+	Header     http.Header `json:"header,omitempty"`
+	Body       Body        `json:"body,omitempty"`
+}
+
+// Body is stored as plain text when it is valid utf-8 and as base64 otherwise
+type Body []byte
+
+func (b Body) MarshalJSON() ([]byte, error) {
+	if utf8.Valid(b) {
+		return json.Marshal(string(b))
+	}
+	return json.Marshal(map[string]string{"base64": base64.StdEncoding.EncodeToString(b)})
+}
+
+func (b *Body) UnmarshalJSON(data []byte) error {
+	var text string
+	if err := json.Unmarshal(data, &text); err == nil {
+		*b = Body(text)
+		return nil
+	}
+	encoded := map[string]string{}
+	if err := json.Unmarshal(data, &encoded); err != nil {
+		return err
+	}
+	decoded, err := base64.StdEncoding.DecodeString(encoded["base64"])
+	if err != nil {
+		return err
+	}
+	*b = decoded
+	return nil
+}
+
+// Key will compute the interaction key from the method, the url and the body hash
+func Key(method string, url string, body []byte) string {
+	bodyHash := sha256.Sum256(body)
+	hash := sha256.Sum256([]byte(method + " " + url + " " + hex.EncodeToString(bodyHash[:])))
+	return hex.EncodeToString(hash[:16])
+}
+
+// MissError is returned in replay mode when no interaction matches the request
+type MissError struct {
+	Request Request
+	// Closest is the recorded interaction nearest to the request, nil when the cassette is empty
+	Closest *Interaction
+	// Diff lists the differences between the closest recorded request and the request
+	Diff []string
+}
+
+func (e *MissError) Error() string {
+	message := fmt.Sprintf("cassette: no interaction recorded for %s %s", e.Request.Method, e.Request.URL)
+	if e.Closest != nil {
+		message += fmt.Sprintf(", closest %s %s differs by %s", e.Closest.Request.Method, e.Closest.Request.URL, strings.Join(e.Diff, ", "))
+	}
+	return message
+}
+
+func (e *MissError) Is(target error) bool {
+	return target == ErrNotRecorded
+}
+
+// Cassette stores every interaction as a json file named after its key in a directory
+type Cassette struct {
+	mu   sync.RWMutex
+	dir  string
+	mode Mode
+}
+
+// New will create a Cassette stored in dir
+func New(dir string, mode Mode) *Cassette {
+	return &Cassette{dir: dir, mode: mode}
+}
+
+// Mode returns the cassette mode
+func (c *Cassette) Mode() Mode {
+	return c.mode
+}
+
+// Save will write the interaction, replacing the one recorded with the same key
+func (c *Cassette) Save(interaction Interaction) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := os.MkdirAll(c.dir, 0755); err != nil {
+		return err
+	}
+	content, err := json.MarshalIndent(interaction, "", "  ")
+	if err != nil {
+		return err
+	}
+	path := c.path(interaction.Key)
+	if err := ioutil.WriteFile(path+".tmp", content, 0644); err != nil {
+		return err
+	}
+	return os.Rename(path+".tmp", path)
+}
+
+// Load will read the interaction recorded with the key, the error matches ErrNotRecorded when there is none
+func (c *Cassette) Load(key string) (*Interaction, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.load(c.path(key))
+}
+
+// Interactions will read every recorded interaction sorted by key
+func (c *Cassette) Interactions() ([]Interaction, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	paths, err := filepath.Glob(filepath.Join(c.dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(paths)
+	interactions := make([]Interaction, 0, len(paths))
+	for _, path := range paths {
+		interaction, err := c.load(path)
+		if err != nil {
+			return nil, err
+		}
+		interactions = append(interactions, *interaction)
+	}
+	return interactions, nil
+}
+
+// Miss will build the MissError of a request by looking for the closest recorded interaction
+func (c *Cassette) Miss(request Request) *MissError {
+	missError := &MissError{Request: request}
+	interactions, err := c.Interactions()
+	if err != nil {
+		return missError
+	}
+	bestScore := -1
+	for i := range interactions {
+		if score := similarity(interactions[i].Request, request); score > bestScore {
+			bestScore = score
+			missError.Closest = &interactions[i]
+		}
+	}
+	if missError.Closest != nil {
+		missError.Diff = Diff(missError.Closest.Request, request)
+	}
+	return missError
+}
+
+// Diff will list the differences of method, url and body between the recorded and the actual request
+func Diff(recorded Request, actual Request) []string {
+	var diff []string
+	if recorded.Method != actual.Method {
+		diff = append(diff, fmt.Sprintf("method %q != %q", recorded.Method, actual.Method))
+	}
+	recordedURL, recordedErr := url.Parse(recorded.URL)
+	actualURL, actualErr := url.Parse(actual.URL)
+	if recordedErr != nil || actualErr != nil {
+		if recorded.URL != actual.URL {
+			diff = append(diff, fmt.Sprintf("url %q != %q", recorded.URL, actual.URL))
+		}
+	} else {
+		if recordedURL.Scheme+"://"+recordedURL.Host != actualURL.Scheme+"://"+actualURL.Host {
+			diff = append(diff, fmt.Sprintf("host %q != %q", recordedURL.Scheme+"://"+recordedURL.Host, actualURL.Scheme+"://"+actualURL.Host))
+		}
+		if recordedURL.Path != actualURL.Path {
+			diff = append(diff, fmt.Sprintf("path %q != %q", recordedURL.Path, actualURL.Path))
+		}
+		diff = append(diff, diffQuery(recordedURL.Query(), actualURL.Query())...)
+	}
+	if string(recorded.Body) != string(actual.Body) {
+		diff = append(diff, fmt.Sprintf("body %q != %q", truncate(recorded.Body), truncate(actual.Body)))
+	}
+	return diff
+}
+
+func diffQuery(recorded url.Values, actual url.Values) []string {
+	names := map[string]struct{}{}
+	for name := range recorded {
+		names[name] = struct{}{}
+	}
+	for name := range actual {
+		names[name] = struct{}{}
+	}
+	sortedNames := make([]string, 0, len(names))
+	for name := range names {
+		sortedNames = append(sortedNames, name)
+	}
+	sort.Strings(sortedNames)
+
+	var diff []string
+	for _, name := range sortedNames {
+		recordedValue, actualValue := strings.Join(recorded[name], ","), strings.Join(actual[name], ",")
+		if _, ok := recorded[name]; !ok {
+			diff = append(diff, fmt.Sprintf("query %s unexpected %q", name, actualValue))
+		} else if _, ok := actual[name]; !ok {
+			diff = append(diff, fmt.Sprintf("query %s missing %q", name, recordedValue))
+		} else if recordedValue != actualValue {
+			diff = append(diff, fmt.Sprintf("query %s %q != %q", name, recordedValue, actualValue))
+		}
+	}
+	return diff
+}
+
+func similarity(recorded Request, actual Request) int {
+	score := 0
+	if recorded.Method == actual.Method {
+		score += 2
+	}
+	recordedURL, recordedErr := url.Parse(recorded.URL)
+	actualURL, actualErr := url.Parse(actual.URL)
+	if recordedErr == nil && actualErr == nil {
+		if recordedURL.Host == actualURL.Host && recordedURL.Path == actualURL.Path {
+			score += 4
+		}
+		if recordedURL.RawQuery == actualURL.RawQuery {
+			score++
+		}
+	}
+	if string(recorded.Body) == string(actual.Body) {
+		score++
+	}
+	return score
+}
+
+func truncate(body Body) string {
+	const maxLength = 64
+	if len(body) > maxLength {
+		return string(body[:maxLength]) + "..."
+	}
+	return string(body)
+}
+
+func (c *Cassette) path(key string) string {
+	return filepath.Join(c.dir, key+".json")
+}
+
+func (c *Cassette) load(path string) (*Interaction, error) {
+	content, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, ErrNotRecorded
+	}
+	if err != nil {
+		return nil, err
+	}
+	interaction := &Interaction{}
+	if err := json.Unmarshal(content, interaction); err != nil {
+		return nil, fmt.Errorf("cassette: %s: %w", path, err)
+	}
+	return interaction, nil
+}
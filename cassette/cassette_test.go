@@ -0,0 +1,94 @@
+package cassette_test
+
+import (
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/gol4ng/logger-http/cassette"
+)
+
+func TestKey(t *testing.T) {
+	key := cassette.Key(http.MethodPost, "http://127.0.0.1/my-fake-url", []byte("body"))
+	assert.Len(t, key, 32)
+	assert.Equal(t, key, cassette.Key(http.MethodPost, "http://127.0.0.1/my-fake-url", []byte("body")))
+	assert.NotEqual(t, key, cassette.Key(http.MethodPut, "http://127.0.0.1/my-fake-url", []byte("body")))
+	assert.NotEqual(t, key, cassette.Key(http.MethodPost, "http://127.0.0.1/my-other-url", []byte("body")))
+	assert.NotEqual(t, key, cassette.Key(http.MethodPost, "http://127.0.0.1/my-fake-url", []byte("other body")))
+}
+
+func TestCassette(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cassette")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	c := cassette.New(dir, cassette.ModeRecord)
+	assert.Equal(t, cassette.ModeRecord, c.Mode())
+
+	_, err = c.Load("unknown")
+	assert.Equal(t, cassette.ErrNotRecorded, err)
+
+	interaction := cassette.Interaction{
+		Key: "my-key",
+		Request: cassette.Request{
+			Method: http.MethodPost,
+			URL:    "http://127.0.0.1/my-fake-url",
+			Header: http.Header{"Content-Type": {"application/octet-stream"}},
+			Body:   cassette.Body{0xff, 0xfe},
+		},
+		Response: cassette.Response{StatusCode: http.StatusOK, Status: "200 OK", Body: cassette.Body("OK")},
+	}
+	assert.Nil(t, c.Save(interaction))
+
+	loaded, err := c.Load("my-key")
+	assert.Nil(t, err)
+	assert.Equal(t, interaction, *loaded)
+
+	interactions, err := c.Interactions()
+	assert.Nil(t, err)
+	assert.Equal(t, []cassette.Interaction{interaction}, interactions)
+}
+
+func TestCassette_Miss(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cassette")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	c := cassette.New(dir, cassette.ModeReplay)
+	missError := c.Miss(cassette.Request{Method: http.MethodGet, URL: "http://127.0.0.1/my-fake-url"})
+	assert.Nil(t, missError.Closest)
+	assert.EqualError(t, missError, "cassette: no interaction recorded for GET http://127.0.0.1/my-fake-url")
+	assert.True(t, errors.Is(missError, cassette.ErrNotRecorded))
+
+	assert.Nil(t, c.Save(cassette.Interaction{Key: "a", Request: cassette.Request{Method: http.MethodGet, URL: "http://127.0.0.1/my-other-url"}}))
+	assert.Nil(t, c.Save(cassette.Interaction{Key: "b", Request: cassette.Request{Method: http.MethodGet, URL: "http://127.0.0.1/my-fake-url?page=1&sort=asc"}}))
+
+	missError = c.Miss(cassette.Request{Method: http.MethodGet, URL: "http://127.0.0.1/my-fake-url?page=2&limit=10", Body: cassette.Body("body")})
+	assert.Equal(t, "b", missError.Closest.Key)
+	assert.Equal(t, []string{
+		`query limit unexpected "10"`,
+		`query page "1" != "2"`,
+		`query sort missing "asc"`,
+		`body "" != "body"`,
+	}, missError.Diff)
+	assert.Contains(t, missError.Error(), `closest GET http://127.0.0.1/my-fake-url?page=1&sort=asc differs by query limit unexpected "10", query page`)
+}
+
+func TestDiff(t *testing.T) {
+	assert.Nil(t, cassette.Diff(
+		cassette.Request{Method: http.MethodGet, URL: "http://127.0.0.1/my-fake-url"},
+		cassette.Request{Method: http.MethodGet, URL: "http://127.0.0.1/my-fake-url"},
+	))
+	assert.Equal(t, []string{
+		`method "GET" != "POST"`,
+		`host "http://127.0.0.1" != "https://localhost"`,
+		`path "/my-fake-url" != "/my-other-url"`,
+	}, cassette.Diff(
+		cassette.Request{Method: http.MethodGet, URL: "http://127.0.0.1/my-fake-url"},
+		cassette.Request{Method: http.MethodPost, URL: "https://localhost/my-other-url"},
+	))
+}
@@ -0,0 +1,50 @@
+package logger_http
+
+import (
+	"time"
+)
+
+// Clock provides every timestamp and duration emitted by the module
+type Clock interface {
+	Now() time.Time
+	Since(t time.Time) time.Duration
+}
+
+// TickerClock is implemented by clocks driving tickers, the watchdog ticks with it when the Clock implements it
+// the ticks channel drops the ticks of a slow receiver like time.Ticker does
+type TickerClock interface {
+	NewTicker(d time.Duration) (ticks <-chan time.Time, stop func())
+}
+
+// RealClock is the default Clock based on time.Now (durations use the monotonic clock)
+var RealClock Clock = realClock{}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time {
+	return time.Now()
+}
+
+func (realClock) Since(t time.Time) time.Duration {
+	return time.Since(t)
+}
+
+func (realClock) NewTicker(d time.Duration) (<-chan time.Time, func()) {
+	ticker := time.NewTicker(d)
+	return ticker.C, ticker.Stop
+}
+
+func newTicker(clock Clock, d time.Duration) (<-chan time.Time, func()) {
+	if tickerClock, ok := clock.(TickerClock); ok {
+		return tickerClock.NewTicker(d)
+	}
+	return realClock{}.NewTicker(d)
+}
+
+// WithClock customizes the clock used for timestamps and durations
+// see loggerhttptest.Clock for a controllable implementation
+func WithClock(clock Clock) Option {
+	return func(o *Options) {
+		o.Clock = clock
+	}
+}
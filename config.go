@@ -0,0 +1,304 @@
+package logger_http
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Config is the declarative configuration of the Options, loadable from a yaml or json document
+// and from environment variables, see LoadConfig, ConfigFromEnv and Config.Options
+type Config struct {
+	// Levels overrides the level of the status codes in range, other status codes keep the default level
+	Levels           []LiveLevel      `json:"levels" yaml:"levels"`
+	RedactedHeaders  []string         `json:"redacted_headers" yaml:"redacted_headers"`
+	URL              URLConfig        `json:"url" yaml:"url"`
+	SampleRate       *float64         `json:"sample_rate" yaml:"sample_rate"`
+	FilteredPaths    []string         `json:"filtered_paths" yaml:"filtered_paths"`
+	BodyCaptureLimit int              `json:"body_capture_limit" yaml:"body_capture_limit"`
+	MaxFieldLength   int              `json:"max_field_length" yaml:"max_field_length"`
+	FieldNames       FieldNamesConfig `json:"field_names" yaml:"field_names"`
+	Routes           []RouteConfig    `json:"routes" yaml:"routes"`
+}
+
+// URLConfig configures the url redaction rules
+type URLConfig struct {
+	RedactedQueryParams []string `json:"redacted_query_params" yaml:"redacted_query_params"`
+	// RedactedPathSegments are regular expressions
+	RedactedPathSegments []string `json:"redacted_path_segments" yaml:"redacted_path_segments"`
+	StripUserinfo        bool     `json:"strip_userinfo" yaml:"strip_userinfo"`
+}
+
+// FieldNamesConfig configures the field naming scheme
+type FieldNamesConfig struct {
+	// Scheme is "default" or "otel"
+	Scheme string            `json:"scheme" yaml:"scheme"`
+	Rename map[string]string `json:"rename" yaml:"rename"`
+}
+
+// RouteConfig overrides the configuration of every request path starting with PathPrefix, see WithRoutePolicy
+type RouteConfig struct {
+	PathPrefix       string      `json:"path_prefix" yaml:"path_prefix"`
+	Levels           []LiveLevel `json:"levels" yaml:"levels"`
+	SampleRate       *float64    `json:"sample_rate" yaml:"sample_rate"`
+	Disabled         bool        `json:"disabled" yaml:"disabled"`
+	BodyCaptureLimit *int        `json:"body_capture_limit" yaml:"body_capture_limit"`
+	RedactedHeaders  []string    `json:"redacted_headers" yaml:"redacted_headers"`
+}
+
+// LoadConfig will decode a yaml or json document, unknown keys are rejected
+func LoadConfig(r io.Reader) (*Config, error) {
+	content, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	config := &Config{}
+	decoder := yaml.NewDecoder(bytes.NewReader(content))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(config); err != nil && err != io.EOF {
+		return nil, err
+	}
+	return config, nil
+}
+
+// ConfigFromEnv will create a Config from the environment variables starting with prefix, see Config.ApplyEnv
+func ConfigFromEnv(prefix string) (*Config, error) {
+	config := &Config{}
+	if err := config.ApplyEnv(prefix, os.LookupEnv); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+// ApplyEnv will override the configuration with the environment variables starting with prefix
+// lists are comma separated and levels use the "from-to:level" form
+// eg: with the "LOGGER_HTTP_" prefix
+//
+//	LOGGER_HTTP_LEVELS=200-299:debug,500-599:critical
+//	LOGGER_HTTP_REDACTED_HEADERS=Authorization,Cookie
+//	LOGGER_HTTP_REDACTED_QUERY_PARAMS=token
+//	LOGGER_HTTP_REDACTED_PATH_SEGMENTS=^[0-9]+$
+//	LOGGER_HTTP_STRIP_USERINFO=true
+//	LOGGER_HTTP_SAMPLE_RATE=0.1
+//	LOGGER_HTTP_FILTERED_PATHS=/health,/metrics
+//	LOGGER_HTTP_BODY_CAPTURE_LIMIT=1024
+//	LOGGER_HTTP_MAX_FIELD_LENGTH=2048
+//	LOGGER_HTTP_FIELD_NAMES_SCHEME=otel
+//
+// routes can only be configured with a document
+func (c *Config) ApplyEnv(prefix string, lookup func(string) (string, bool)) error {
+	var err error
+	env := func(name string, apply func(value string) error) {
+		value, ok := lookup(prefix + name)
+		if err != nil || !ok {
+			return
+		}
+		if applyErr := apply(value); applyErr != nil {
+			err = fmt.Errorf("%s%s: %w", prefix, name, applyErr)
+		}
+	}
+	env("LEVELS", func(value string) error {
+		levels, parseErr := parseEnvLevels(value)
+		c.Levels = levels
+		return parseErr
+	})
+	env("REDACTED_HEADERS", func(value string) error {
+		c.RedactedHeaders = splitEnvList(value)
+		return nil
+	})
+	env("REDACTED_QUERY_PARAMS", func(value string) error {
+		c.URL.RedactedQueryParams = splitEnvList(value)
+		return nil
+	})
+	env("REDACTED_PATH_SEGMENTS", func(value string) error {
+		c.URL.RedactedPathSegments = splitEnvList(value)
+		return nil
+	})
+	env("STRIP_USERINFO", func(value string) (parseErr error) {
+		c.URL.StripUserinfo, parseErr = strconv.ParseBool(value)
+		return parseErr
+	})
+	env("SAMPLE_RATE", func(value string) error {
+		rate, parseErr := strconv.ParseFloat(value, 64)
+		c.SampleRate = &rate
+		return parseErr
+	})
+	env("FILTERED_PATHS", func(value string) error {
+		c.FilteredPaths = splitEnvList(value)
+		return nil
+	})
+	env("BODY_CAPTURE_LIMIT", func(value string) (parseErr error) {
+		c.BodyCaptureLimit, parseErr = strconv.Atoi(value)
+		return parseErr
+	})
+	env("MAX_FIELD_LENGTH", func(value string) (parseErr error) {
+		c.MaxFieldLength, parseErr = strconv.Atoi(value)
+		return parseErr
+	})
+	env("FIELD_NAMES_SCHEME", func(value string) error {
+		c.FieldNames.Scheme = value
+		return nil
+	})
+	return err
+}
+
+// Options will validate the configuration and convert it into options
+// validation errors are prefixed with the offending key
+func (c *Config) Options() ([]Option, error) {
+	var opts []Option
+	if len(c.Levels) > 0 {
+		levels, err := compileLiveLevels(c.Levels)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, withRangeLevels(levels))
+	}
+	if len(c.RedactedHeaders) > 0 {
+		opts = append(opts, WithRedactedHeaders(c.RedactedHeaders...))
+	}
+	if len(c.URL.RedactedQueryParams) > 0 {
+		opts = append(opts, WithRedactedQueryParams(c.URL.RedactedQueryParams...))
+	}
+	if len(c.URL.RedactedPathSegments) > 0 {
+		patterns := make([]*regexp.Regexp, 0, len(c.URL.RedactedPathSegments))
+		for i, segment := range c.URL.RedactedPathSegments {
+			pattern, err := regexp.Compile(segment)
+			if err != nil {
+				return nil, fmt.Errorf("url.redacted_path_segments[%d]: %w", i, err)
+			}
+			patterns = append(patterns, pattern)
+		}
+		opts = append(opts, WithRedactedPathSegments(patterns...))
+	}
+	if c.URL.StripUserinfo {
+		opts = append(opts, WithStripUserinfo())
+	}
+	if c.SampleRate != nil {
+		if err := validateSampleRate(*c.SampleRate); err != nil {
+			return nil, fmt.Errorf("sample_rate: %w", err)
+		}
+		opts = append(opts, WithSampler(RateSampler(*c.SampleRate)))
+	}
+	if len(c.FilteredPaths) > 0 {
+		opts = append(opts, WithRequestFilter(PathFilter(c.FilteredPaths...)))
+	}
+	if c.BodyCaptureLimit < 0 {
+		return nil, fmt.Errorf("body_capture_limit: %d must be positive", c.BodyCaptureLimit)
+	}
+	if c.BodyCaptureLimit > 0 {
+		opts = append(opts, WithBodyCapture(c.BodyCaptureLimit))
+	}
+	if c.MaxFieldLength < 0 {
+		return nil, fmt.Errorf("max_field_length: %d must be positive", c.MaxFieldLength)
+	}
+	if c.MaxFieldLength > 0 {
+		opts = append(opts, WithMaxFieldLength(c.MaxFieldLength))
+	}
+	switch c.FieldNames.Scheme {
+	case "", "default":
+	case "otel":
+		opts = append(opts, WithFieldNames(OTelFieldNames))
+	default:
+		return nil, fmt.Errorf("field_names.scheme: unknown scheme %q", c.FieldNames.Scheme)
+	}
+	if len(c.FieldNames.Rename) > 0 {
+		opts = append(opts, WithFieldNames(c.FieldNames.Rename))
+	}
+	for i, route := range c.Routes {
+		routeOpts, err := route.options()
+		if err != nil {
+			return nil, fmt.Errorf("routes[%d].%w", i, err)
+		}
+		opts = append(opts, WithRoutePolicy(route.PathPrefix, routeOpts...))
+	}
+	return opts, nil
+}
+
+func (r RouteConfig) options() ([]Option, error) {
+	if !strings.HasPrefix(r.PathPrefix, "/") {
+		return nil, fmt.Errorf("path_prefix: %q must start with /", r.PathPrefix)
+	}
+	var opts []Option
+	if len(r.Levels) > 0 {
+		levels, err := compileLiveLevels(r.Levels)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, withRangeLevels(levels))
+	}
+	if r.SampleRate != nil {
+		if err := validateSampleRate(*r.SampleRate); err != nil {
+			return nil, fmt.Errorf("sample_rate: %w", err)
+		}
+		opts = append(opts, WithSampler(RateSampler(*r.SampleRate)))
+	}
+	if r.Disabled {
+		opts = append(opts, WithRequestFilter(func(*http.Request) bool { return true }))
+	}
+	if r.BodyCaptureLimit != nil {
+		if *r.BodyCaptureLimit < 0 {
+			return nil, fmt.Errorf("body_capture_limit: %d must be positive", *r.BodyCaptureLimit)
+		}
+		opts = append(opts, WithBodyCapture(*r.BodyCaptureLimit))
+	}
+	if len(r.RedactedHeaders) > 0 {
+		opts = append(opts, WithRedactedHeaders(r.RedactedHeaders...))
+	}
+	return opts, nil
+}
+
+func withRangeLevels(levels []liveLevel) Option {
+	return func(o *Options) {
+		o.LevelFunc = rangeLevels(levels, o.LevelFunc)
+	}
+}
+
+func validateSampleRate(rate float64) error {
+	if rate < 0 || rate > 1 {
+		return fmt.Errorf("%v must be between 0 and 1", rate)
+	}
+	return nil
+}
+
+func splitEnvList(value string) []string {
+	var list []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
+
+func parseEnvLevels(value string) ([]LiveLevel, error) {
+	var levels []LiveLevel
+	for _, item := range splitEnvList(value) {
+		invalid := fmt.Errorf("%q must use the from-to:level form", item)
+		index := strings.LastIndexByte(item, ':')
+		if index < 0 {
+			return nil, invalid
+		}
+		bounds := strings.SplitN(item[:index], "-", 2)
+		if len(bounds) != 2 {
+			return nil, invalid
+		}
+		from, err := strconv.Atoi(strings.TrimSpace(bounds[0]))
+		if err != nil {
+			return nil, invalid
+		}
+		to, err := strconv.Atoi(strings.TrimSpace(bounds[1]))
+		if err != nil {
+			return nil, invalid
+		}
+		levels = append(levels, LiveLevel{From: from, To: to, Level: strings.TrimSpace(item[index+1:])})
+	}
+	return levels, nil
+}
@@ -0,0 +1,147 @@
+package logger_http_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/gol4ng/logger"
+	"github.com/stretchr/testify/assert"
+
+	logger_http "github.com/gol4ng/logger-http"
+)
+
+func TestLoadConfig(t *testing.T) {
+	config, err := logger_http.LoadConfig(strings.NewReader(`
+levels:
+  - {from: 200, to: 299, level: debug}
+redacted_headers: [Authorization]
+url:
+  redacted_query_params: [token]
+  redacted_path_segments: ['^[0-9]+$']
+  strip_userinfo: true
+filtered_paths: [/health]
+body_capture_limit: 1024
+max_field_length: 2048
+field_names:
+  scheme: otel
+  rename:
+    http_kind: kind
+routes:
+  - path_prefix: /api/upload
+    body_capture_limit: 0
+    sample_rate: 0.5
+    levels:
+      - {from: 400, to: 499, level: error}
+  - path_prefix: /internal
+    disabled: true
+`))
+	assert.Nil(t, err)
+
+	opts, err := config.Options()
+	assert.Nil(t, err)
+	o := logger_http.EvaluateServerOpt(opts...)
+
+	assert.Equal(t, logger.DebugLevel, o.LevelFunc(http.StatusOK))
+	assert.Equal(t, logger.WarningLevel, o.LevelFunc(http.StatusNotFound))
+	assert.Equal(t, []string{"Authorization"}, o.RedactedHeaders)
+	assert.Equal(t, "/users/[REDACTED]?token=[REDACTED]", o.SanitizeURL(&url.URL{User: url.User("me"), Path: "/users/42", RawQuery: "token=secret"}))
+	assert.True(t, o.RequestFilter(httptest.NewRequest(http.MethodGet, "/health", nil)))
+	assert.Nil(t, o.Sampler)
+	assert.Equal(t, 1024, o.BodyCaptureLimit)
+	assert.Equal(t, 2048, o.MaxFieldLength)
+	assert.Equal(t, "url.full", o.FieldNames["http_url"])
+	assert.Equal(t, "kind", o.FieldNames["http_kind"])
+
+	uploadPolicy := o.Policy(httptest.NewRequest(http.MethodPost, "/api/upload", nil))
+	assert.Equal(t, 0, uploadPolicy.BodyCaptureLimit)
+	assert.NotNil(t, uploadPolicy.Sampler)
+	assert.Equal(t, logger.ErrorLevel, uploadPolicy.LevelFunc(http.StatusNotFound))
+	assert.Equal(t, logger.DebugLevel, uploadPolicy.LevelFunc(http.StatusOK))
+
+	internalRequest := httptest.NewRequest(http.MethodGet, "/internal/status", nil)
+	assert.True(t, o.Policy(internalRequest).RequestFilter(internalRequest))
+}
+
+func TestLoadConfig_JSON(t *testing.T) {
+	config, err := logger_http.LoadConfig(strings.NewReader(`{"redacted_headers": ["Cookie"], "sample_rate": 0.1}`))
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"Cookie"}, config.RedactedHeaders)
+	assert.Equal(t, 0.1, *config.SampleRate)
+
+	config, err = logger_http.LoadConfig(strings.NewReader(``))
+	assert.Nil(t, err)
+	assert.Equal(t, &logger_http.Config{}, config)
+
+	_, err = logger_http.LoadConfig(strings.NewReader(`{"redacted_header": ["Cookie"]}`))
+	assert.EqualError(t, err, "yaml: unmarshal errors:\n  line 1: field redacted_header not found in type logger_http.Config")
+}
+
+func TestConfig_ApplyEnv(t *testing.T) {
+	env := map[string]string{
+		"LOGGER_HTTP_LEVELS":                 "200-299:debug, 500-599:critical",
+		"LOGGER_HTTP_REDACTED_HEADERS":       "Authorization, Cookie",
+		"LOGGER_HTTP_REDACTED_QUERY_PARAMS":  "token",
+		"LOGGER_HTTP_STRIP_USERINFO":         "true",
+		"LOGGER_HTTP_SAMPLE_RATE":            "0.25",
+		"LOGGER_HTTP_FILTERED_PATHS":         "/health,/metrics",
+		"LOGGER_HTTP_BODY_CAPTURE_LIMIT":     "512",
+		"LOGGER_HTTP_FIELD_NAMES_SCHEME":     "otel",
+		"LOGGER_HTTP_REDACTED_PATH_SEGMENTS": "^[0-9]+$",
+	}
+	lookup := func(name string) (string, bool) {
+		value, ok := env[name]
+		return value, ok
+	}
+
+	config := &logger_http.Config{MaxFieldLength: 100}
+	assert.Nil(t, config.ApplyEnv("LOGGER_HTTP_", lookup))
+	assert.Equal(t, &logger_http.Config{
+		Levels:          []logger_http.LiveLevel{{From: 200, To: 299, Level: "debug"}, {From: 500, To: 599, Level: "critical"}},
+		RedactedHeaders: []string{"Authorization", "Cookie"},
+		URL: logger_http.URLConfig{
+			RedactedQueryParams:  []string{"token"},
+			RedactedPathSegments: []string{"^[0-9]+$"},
+			StripUserinfo:        true,
+		},
+		SampleRate:       config.SampleRate,
+		FilteredPaths:    []string{"/health", "/metrics"},
+		BodyCaptureLimit: 512,
+		MaxFieldLength:   100,
+		FieldNames:       logger_http.FieldNamesConfig{Scheme: "otel"},
+	}, config)
+	assert.Equal(t, 0.25, *config.SampleRate)
+
+	env["LOGGER_HTTP_LEVELS"] = "200:debug"
+	assert.EqualError(t, config.ApplyEnv("LOGGER_HTTP_", lookup), `LOGGER_HTTP_LEVELS: "200:debug" must use the from-to:level form`)
+
+	env["LOGGER_HTTP_LEVELS"] = ""
+	env["LOGGER_HTTP_SAMPLE_RATE"] = "often"
+	assert.EqualError(t, config.ApplyEnv("LOGGER_HTTP_", lookup), `LOGGER_HTTP_SAMPLE_RATE: strconv.ParseFloat: parsing "often": invalid syntax`)
+}
+
+func TestConfig_Options_Invalid(t *testing.T) {
+	rate := 2.0
+	limit := -1
+	tests := []struct {
+		config   logger_http.Config
+		expected string
+	}{
+		{config: logger_http.Config{Levels: []logger_http.LiveLevel{{From: 200, To: 299, Level: "verbose"}}}, expected: `levels[0].level: unknown level "verbose"`},
+		{config: logger_http.Config{URL: logger_http.URLConfig{RedactedPathSegments: []string{"[0-9"}}}, expected: "url.redacted_path_segments[0]: error parsing regexp: missing closing ]: `[0-9`"},
+		{config: logger_http.Config{SampleRate: &rate}, expected: "sample_rate: 2 must be between 0 and 1"},
+		{config: logger_http.Config{BodyCaptureLimit: -1}, expected: "body_capture_limit: -1 must be positive"},
+		{config: logger_http.Config{FieldNames: logger_http.FieldNamesConfig{Scheme: "ecs"}}, expected: `field_names.scheme: unknown scheme "ecs"`},
+		{config: logger_http.Config{Routes: []logger_http.RouteConfig{{PathPrefix: "/"}, {PathPrefix: "api"}}}, expected: `routes[1].path_prefix: "api" must start with /`},
+		{config: logger_http.Config{Routes: []logger_http.RouteConfig{{PathPrefix: "/", BodyCaptureLimit: &limit}}}, expected: "routes[0].body_capture_limit: -1 must be positive"},
+		{config: logger_http.Config{Routes: []logger_http.RouteConfig{{PathPrefix: "/", Levels: []logger_http.LiveLevel{{From: 500, To: 400, Level: "info"}}}}}, expected: "routes[0].levels[0]: from 500 is greater than to 400"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expected, func(t *testing.T) {
+			_, err := tt.config.Options()
+			assert.EqualError(t, err, tt.expected)
+		})
+	}
+}
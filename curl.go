@@ -0,0 +1,153 @@
+package logger_http
+
+import (
+	"io"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// StatusRange is an inclusive range of http status codes
+type StatusRange struct {
+	From int
+	To   int
+}
+
+// Contains will return true when the status code is in the range
+func (r StatusRange) Contains(statusCode int) bool {
+	return statusCode >= r.From && statusCode <= r.To
+}
+
+// WithCurl will add a http_curl field with an equivalent curl command to the client logs
+// on transport error and when the response status code is in one of the given ranges
+// eg: WithCurl(logger_http.StatusRange{From: 500, To: 599})
+func WithCurl(ranges ...StatusRange) Option {
+	return func(o *Options) {
+		o.Curl = true
+		o.CurlStatusRanges = append(append([]StatusRange{}, o.CurlStatusRanges...), ranges...)
+	}
+}
+
+// ShouldCurl will return true when the curl command must be attached for the status code, 0 meaning a transport error
+func (o *Options) ShouldCurl(statusCode int) bool {
+	if !o.Curl {
+		return false
+	}
+	if statusCode == 0 {
+		return true
+	}
+	for _, statusRange := range o.CurlStatusRanges {
+		if statusRange.Contains(statusCode) {
+			return true
+		}
+	}
+	return false
+}
+
+// CurlCommand will build the curl command reproducing the request
+// the url and headers are redacted with the configured rules and the body is only added when replayable via GetBody
+// the body is capped to the WithBodyCapture or WithMaxFieldLength limit (DefaultCurlBodyLimit otherwise) and suffixed with TruncatedMarker
+func (o *Options) CurlCommand(req *http.Request) string {
+	args := []string{"curl"}
+	if req.Method != "" && req.Method != http.MethodGet {
+		args = append(args, "-X", ShellQuote(req.Method))
+	}
+	args = append(args, ShellQuote(o.SanitizeURL(req.URL)))
+
+	header := RedactHeader(req.Header, o.RedactedHeaders...)
+	if req.Host != "" && req.URL != nil && req.Host != req.URL.Host {
+		header.Set("Host", req.Host)
+	}
+	names := make([]string, 0, len(header))
+	for name := range header {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		for _, value := range header[name] {
+			args = append(args, "-H", ShellQuote(name+": "+value))
+		}
+	}
+
+	if req.GetBody != nil {
+		if body, err := req.GetBody(); err == nil && body != nil {
+			limit := o.curlBodyLimit()
+			// one more byte tells a body of exactly limit bytes from a longer one
+			content, err := ioutil.ReadAll(io.LimitReader(body, int64(limit)+1))
+			body.Close()
+			if err == nil && len(content) > 0 {
+				data := string(content)
+				if len(content) > limit {
+					data = string(content[:limit]) + TruncatedMarker
+				}
+				args = append(args, "--data-binary", ShellQuote(data))
+			}
+		}
+	}
+	return strings.Join(args, " ")
+}
+
+// DefaultCurlBodyLimit is the curl command body cap used when neither WithBodyCapture nor WithMaxFieldLength is set
+const DefaultCurlBodyLimit = 4096
+
+// curlBodyLimit will return the smallest configured body or field cap
+func (o *Options) curlBodyLimit() int {
+	limit := DefaultCurlBodyLimit
+	if o.BodyCaptureLimit > 0 {
+		limit = o.BodyCaptureLimit
+	}
+	if o.MaxFieldLength > 0 && o.MaxFieldLength < limit {
+		limit = o.MaxFieldLength
+	}
+	return limit
+}
+
+// ShellQuote will quote the value for a POSIX shell
+// values with control or non utf-8 characters use the $'...' form so the command stays on a single line
+func ShellQuote(value string) string {
+	if value != "" && strings.IndexFunc(value, needsShellQuote) < 0 {
+		return value
+	}
+	if strings.IndexFunc(value, needsEscape) < 0 {
+		return "'" + strings.Replace(value, "'", `'\''`, -1) + "'"
+	}
+	builder := strings.Builder{}
+	builder.WriteString("$'")
+	for i := 0; i < len(value); {
+		r, size := utf8.DecodeRuneInString(value[i:])
+		switch {
+		case r == '\'' || r == '\\':
+			builder.WriteByte('\\')
+			builder.WriteRune(r)
+		case r == '\n':
+			builder.WriteString(`\n`)
+		case r == '\r':
+			builder.WriteString(`\r`)
+		case r == '\t':
+			builder.WriteString(`\t`)
+		case needsEscape(r):
+			for _, b := range []byte(value[i : i+size]) {
+				builder.WriteString(`\x`)
+				builder.WriteByte(hexDigits[b>>4])
+				builder.WriteByte(hexDigits[b&0xf])
+			}
+		default:
+			builder.WriteRune(r)
+		}
+		i += size
+	}
+	builder.WriteString("'")
+	return builder.String()
+}
+
+const hexDigits = "0123456789abcdef"
+
+func needsShellQuote(r rune) bool {
+	if r < utf8.RuneSelf && (unicode.IsLetter(r) || unicode.IsDigit(r)) {
+		return false
+	}
+	return !strings.ContainsRune("-_./:=@,+%", r)
+}
@@ -0,0 +1,92 @@
+package logger_http_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	logger_http "github.com/gol4ng/logger-http"
+)
+
+func TestShellQuote(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected string
+	}{
+		{value: "", expected: "''"},
+		{value: "http://127.0.0.1/my-fake-url", expected: "http://127.0.0.1/my-fake-url"},
+		{value: "http://127.0.0.1/my-fake-url?page=1", expected: "'http://127.0.0.1/my-fake-url?page=1'"},
+		{value: "Content-Type: application/json", expected: "'Content-Type: application/json'"},
+		{value: "it's", expected: `'it'\''s'`},
+		{value: "$(rm -rf /)", expected: "'$(rm -rf /)'"},
+		{value: "line1\nit's\\", expected: `$'line1\nit\'s\\'`},
+		{value: "\x1b[31m\xff", expected: `$'\x1b[31m\xff'`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			assert.Equal(t, tt.expected, logger_http.ShellQuote(tt.value))
+		})
+	}
+}
+
+func TestOptions_ShouldCurl(t *testing.T) {
+	assert.False(t, logger_http.EvaluateClientOpt().ShouldCurl(0))
+
+	o := logger_http.EvaluateClientOpt(logger_http.WithCurl(logger_http.StatusRange{From: 500, To: 599}, logger_http.StatusRange{From: 429, To: 429}))
+	assert.True(t, o.ShouldCurl(0))
+	assert.True(t, o.ShouldCurl(http.StatusTooManyRequests))
+	assert.True(t, o.ShouldCurl(http.StatusBadGateway))
+	assert.False(t, o.ShouldCurl(http.StatusOK))
+	assert.False(t, o.ShouldCurl(http.StatusNotFound))
+}
+
+func TestOptions_CurlCommand(t *testing.T) {
+	o := logger_http.EvaluateClientOpt(
+		logger_http.WithRedactedHeaders("Authorization"),
+		logger_http.WithRedactedQueryParams("token"),
+	)
+
+	request, _ := http.NewRequest(http.MethodPost, "http://127.0.0.1/my-fake-url?token=secret", strings.NewReader(`{"name":"it's me"}`))
+	request.Header.Set("Authorization", "Bearer secret")
+	request.Header.Set("Content-Type", "application/json")
+	request.Host = "my-host"
+	assert.Equal(t,
+		`curl -X POST 'http://127.0.0.1/my-fake-url?token=[REDACTED]' -H 'Authorization: [REDACTED]' -H 'Content-Type: application/json' -H 'Host: my-host' --data-binary '{"name":"it'\''s me"}'`,
+		o.CurlCommand(request),
+	)
+	assert.Equal(t, "Bearer secret", request.Header.Get("Authorization"))
+
+	assert.Equal(t, "curl http://127.0.0.1/my-fake-url", o.CurlCommand(httptest.NewRequest(http.MethodGet, "http://127.0.0.1/my-fake-url", nil)))
+
+	notReplayable := httptest.NewRequest(http.MethodPut, "http://127.0.0.1/my-fake-url", strings.NewReader("body"))
+	notReplayable.Host = "127.0.0.1"
+	assert.Equal(t, "curl -X PUT http://127.0.0.1/my-fake-url", o.CurlCommand(notReplayable))
+}
+
+func TestOptions_CurlCommand_BodyLimit(t *testing.T) {
+	newRequest := func(body string) *http.Request {
+		request, _ := http.NewRequest(http.MethodPost, "http://127.0.0.1/my-fake-url", strings.NewReader(body))
+		return request
+	}
+
+	tests := []struct {
+		name         string
+		opts         []logger_http.Option
+		body         string
+		expectedData string
+	}{
+		{name: "body capture limit", opts: []logger_http.Option{logger_http.WithBodyCapture(7)}, body: "my request body", expectedData: "'my requ" + logger_http.TruncatedMarker + "'"},
+		{name: "max field length", opts: []logger_http.Option{logger_http.WithBodyCapture(7), logger_http.WithMaxFieldLength(2)}, body: "my request body", expectedData: "'my" + logger_http.TruncatedMarker + "'"},
+		{name: "body at the limit", opts: []logger_http.Option{logger_http.WithBodyCapture(7)}, body: "my body", expectedData: "'my body'"},
+		{name: "default limit", body: strings.Repeat("a", logger_http.DefaultCurlBodyLimit+1), expectedData: "'" + strings.Repeat("a", logger_http.DefaultCurlBodyLimit) + logger_http.TruncatedMarker + "'"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o := logger_http.EvaluateClientOpt(tt.opts...)
+			assert.Equal(t, "curl -X POST http://127.0.0.1/my-fake-url --data-binary "+tt.expectedData, o.CurlCommand(newRequest(tt.body)))
+		})
+	}
+}
@@ -0,0 +1,185 @@
+package logger_http
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gol4ng/logger"
+	"github.com/gol4ng/logger/middleware"
+)
+
+const (
+	// DefaultDebugHeader is the header carrying the debug token
+	DefaultDebugHeader = "X-Debug-Token"
+	// DefaultDebugBodyLimit is the body capture and dump limit of a debugged request
+	DefaultDebugBodyLimit = 4096
+	// DebugFieldName is the field added to every entry of a debugged request
+	DebugFieldName = "http_debug"
+	// DefaultDebugMaxTTL caps the lifetime of the accepted debug tokens when no maxTTL is given
+	DefaultDebugMaxTTL = time.Hour
+)
+
+type debugContextKey struct{}
+
+// debugDumpSwitch dumps every request, it is used for debugged requests
+var debugDumpSwitch = func() *DumpSwitch {
+	dumpSwitch := NewDumpSwitch()
+	dumpSwitch.EnableAll(true)
+	return dumpSwitch
+}()
+
+// NewDebugToken will create a debug token signed with the key for the audience and valid until expiresAt
+// the token format is "<expiry unix timestamp>.<hex hmac-sha256 of the audience and the expiry>"
+// the audience scopes the token (eg: a service or platform name), it is only accepted by the verifiers of the same audience
+func NewDebugToken(key []byte, audience string, expiresAt time.Time) string {
+	expiry := strconv.FormatInt(expiresAt.Unix(), 10)
+	return expiry + "." + debugSignature(key, audience, expiry)
+}
+
+// VerifyDebugToken will return true when the token is signed with the key for the audience and not expired at now
+// tokens expiring later than now + maxTTL are rejected, a maxTTL lower or equal to 0 means DefaultDebugMaxTTL
+func VerifyDebugToken(key []byte, audience string, token string, now time.Time, maxTTL time.Duration) bool {
+	if len(key) == 0 {
+		return false
+	}
+	if maxTTL <= 0 {
+		maxTTL = DefaultDebugMaxTTL
+	}
+	index := strings.IndexByte(token, '.')
+	if index < 0 {
+		return false
+	}
+	expiry, signature := token[:index], token[index+1:]
+	if !hmac.Equal([]byte(signature), []byte(debugSignature(key, audience, expiry))) {
+		return false
+	}
+	unix, err := strconv.ParseInt(expiry, 10, 64)
+	if err != nil {
+		return false
+	}
+	expiresAt := time.Unix(unix, 0)
+	if !now.Before(expiresAt) {
+		return false
+	}
+	return !expiresAt.After(now.Add(maxTTL))
+}
+
+func debugSignature(key []byte, audience string, expiry string) string {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(audience))
+	// the separator keeps the audience and expiry boundary unambiguous
+	mac.Write([]byte{0})
+	mac.Write([]byte(expiry))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// WithDebugToken will enable verbose logging of the requests carrying a valid debug token signed with the key for the audience
+// see NewDebugToken, tokens expiring later than maxTTL are rejected (DefaultDebugMaxTTL when maxTTL is lower or equal to 0)
+// the debug header is redacted from the logs
+// the middleware only tags the entries of a debugged request with the http_debug field, it cannot lower the level of the logger handler:
+// the logger must filter with DebugLevelFilter instead of logger/middleware.MinLevelFilter for the debug entries to be written
+// eg: logger.NewLogger(logger_http.DebugLevelFilter(logger.InfoLevel)(handler.Stream(os.Stdout, formatter.NewDefaultFormatter())))
+func WithDebugToken(key []byte, audience string, maxTTL time.Duration) Option {
+	return func(o *Options) {
+		o.DebugKey = key
+		o.DebugAudience = audience
+		o.DebugMaxTTL = maxTTL
+	}
+}
+
+// WithDebugHeader customizes the header carrying the debug token, DefaultDebugHeader by default
+func WithDebugHeader(headerName string) Option {
+	return func(o *Options) {
+		o.DebugHeader = headerName
+	}
+}
+
+// WithDebugBodyLimit customizes the body capture and dump limit of a debugged request, DefaultDebugBodyLimit by default
+func WithDebugBodyLimit(limit int) Option {
+	return func(o *Options) {
+		o.DebugBodyLimit = limit
+	}
+}
+
+// WithDebugForwardHosts will allow tripperware.Logger to forward the debug token to the given hosts
+// hosts are matched case insensitively against the url host, with or without its port (eg: "api.internal" or "api.internal:8080")
+// the token is not forwarded by default: any receiver can replay it within its TTL against the services sharing the audience
+func WithDebugForwardHosts(hosts ...string) Option {
+	return func(o *Options) {
+		o.DebugForwardHosts = hosts
+	}
+}
+
+// ShouldForwardDebugToken will return true when the debug token can be sent to the host of the url, see WithDebugForwardHosts
+func (o *Options) ShouldForwardDebugToken(u *url.URL) bool {
+	for _, host := range o.DebugForwardHosts {
+		if strings.EqualFold(host, u.Host) || strings.EqualFold(host, u.Hostname()) {
+			return true
+		}
+	}
+	return false
+}
+
+// DebugToken will return the verified debug token of the request
+// it is read from the go-context when an upstream middleware already verified it, from the debug header otherwise
+func (o *Options) DebugToken(request *http.Request) string {
+	if token := DebugTokenFromContext(request.Context()); token != "" {
+		return token
+	}
+	if token := request.Header.Get(o.DebugHeader); token != "" && VerifyDebugToken(o.DebugKey, o.DebugAudience, token, o.Clock.Now(), o.DebugMaxTTL) {
+		return token
+	}
+	return ""
+}
+
+// DebugPolicy will return a copy of the options logging every entry with body capture and dumps enabled
+func (o *Options) DebugPolicy() *Options {
+	debugPolicy := *o
+	debugPolicy.Sampler = nil
+	debugPolicy.MinLevel = logger.DebugLevel
+	debugPolicy.TailBufferLimit = 0
+	debugPolicy.Dump = debugDumpSwitch
+	if debugPolicy.BodyCaptureLimit < o.DebugBodyLimit {
+		debugPolicy.BodyCaptureLimit = o.DebugBodyLimit
+	}
+	if debugPolicy.DumpBodyLimit < o.DebugBodyLimit {
+		debugPolicy.DumpBodyLimit = o.DebugBodyLimit
+	}
+	return &debugPolicy
+}
+
+// InjectDebugTokenInContext will inject a verified debug token into the go-context
+// tripperware.Logger forwards it on outbound requests to the WithDebugForwardHosts hosts,
+// the downstream services only accept it when they share the key and audience
+func InjectDebugTokenInContext(ctx context.Context, token string) context.Context {
+	return context.WithValue(ctx, debugContextKey{}, token)
+}
+
+// DebugTokenFromContext will retrieve the verified debug token from the go-context or return an empty string
+func DebugTokenFromContext(ctx context.Context) string {
+	token, _ := ctx.Value(debugContextKey{}).(string)
+	return token
+}
+
+// DebugLevelFilter is a gol4ng logger middleware excluding entries with a level minor than the given level
+// except the entries of debugged requests, it replaces logger/middleware.MinLevelFilter
+// eg: logger.NewLogger(logger_http.DebugLevelFilter(logger.InfoLevel)(handler.Stream(os.Stdout, formatter.NewDefaultFormatter())))
+func DebugLevelFilter(level logger.Level) logger.MiddlewareInterface {
+	return middleware.Filter(func(entry logger.Entry) bool {
+		if entry.Level <= level {
+			return false
+		}
+		if entry.Context == nil {
+			return true
+		}
+		field, ok := (*entry.Context)[DebugFieldName]
+		return !ok || field.Value != true
+	})
+}
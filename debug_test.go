@@ -0,0 +1,96 @@
+package logger_http_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gol4ng/logger"
+	testing_logger "github.com/gol4ng/logger/testing"
+	"github.com/stretchr/testify/assert"
+
+	logger_http "github.com/gol4ng/logger-http"
+	"github.com/gol4ng/logger-http/loggerhttptest"
+)
+
+func TestVerifyDebugToken(t *testing.T) {
+	key := []byte("my-key")
+	now := time.Date(2019, 12, 13, 17, 1, 13, 0, time.UTC)
+	token := logger_http.NewDebugToken(key, "my-service", now.Add(10*time.Minute))
+	assert.Equal(t, "1576257073.", token[:11])
+
+	assert.True(t, logger_http.VerifyDebugToken(key, "my-service", token, now, 0))
+	assert.True(t, logger_http.VerifyDebugToken(key, "my-service", token, now, 10*time.Minute))
+	assert.False(t, logger_http.VerifyDebugToken(key, "my-service", token, now, 5*time.Minute))
+	assert.False(t, logger_http.VerifyDebugToken(key, "my-service", token, now.Add(10*time.Minute), 0))
+	assert.False(t, logger_http.VerifyDebugToken(key, "other-service", token, now, 0))
+	assert.False(t, logger_http.VerifyDebugToken(key, "", token, now, 0))
+	assert.False(t, logger_http.VerifyDebugToken([]byte("other-key"), "my-service", token, now, 0))
+	assert.False(t, logger_http.VerifyDebugToken(nil, "my-service", token, now, 0))
+	assert.False(t, logger_http.VerifyDebugToken(key, "my-service", "1576257673"+token[10:], now, 0))
+	assert.False(t, logger_http.VerifyDebugToken(key, "my-service", "invalid", now, 0))
+}
+
+func TestVerifyDebugToken_DefaultMaxTTL(t *testing.T) {
+	key := []byte("my-key")
+	now := time.Date(2019, 12, 13, 17, 1, 13, 0, time.UTC)
+
+	assert.True(t, logger_http.VerifyDebugToken(key, "my-service", logger_http.NewDebugToken(key, "my-service", now.Add(logger_http.DefaultDebugMaxTTL)), now, 0))
+	assert.False(t, logger_http.VerifyDebugToken(key, "my-service", logger_http.NewDebugToken(key, "my-service", now.Add(logger_http.DefaultDebugMaxTTL+time.Second)), now, 0))
+	assert.False(t, logger_http.VerifyDebugToken(key, "my-service", logger_http.NewDebugToken(key, "my-service", now.AddDate(10, 0, 0)), now, -1))
+}
+
+func TestOptions_DebugToken(t *testing.T) {
+	key := []byte("my-key")
+	clock := loggerhttptest.NewClock(time.Date(2019, 12, 13, 17, 1, 13, 0, time.UTC))
+	o := logger_http.EvaluateServerOpt(logger_http.WithDebugToken(key, "my-service", time.Hour), logger_http.WithClock(clock))
+	token := logger_http.NewDebugToken(key, "my-service", clock.Now().Add(time.Minute))
+
+	request := httptest.NewRequest(http.MethodGet, "http://127.0.0.1/my-fake-url", nil)
+	assert.Equal(t, "", o.DebugToken(request))
+
+	request.Header.Set(logger_http.DefaultDebugHeader, token)
+	assert.Equal(t, token, o.DebugToken(request))
+
+	clock.Add(time.Minute)
+	assert.Equal(t, "", o.DebugToken(request))
+
+	request = request.WithContext(logger_http.InjectDebugTokenInContext(request.Context(), "verified-token"))
+	assert.Equal(t, "verified-token", o.DebugToken(request))
+	assert.Equal(t, "verified-token", logger_http.DebugTokenFromContext(request.Context()))
+	assert.Equal(t, "", logger_http.DebugTokenFromContext(context.Background()))
+
+	assert.Equal(t, []string{logger_http.DefaultDebugHeader}, o.RedactedHeaders)
+}
+
+func TestOptions_DebugPolicy(t *testing.T) {
+	o := logger_http.EvaluateServerOpt(
+		logger_http.WithSampler(logger_http.RateSampler(0)),
+		logger_http.WithBodyCapture(10),
+		logger_http.WithDebugBodyLimit(1024),
+	)
+	debugPolicy := o.DebugPolicy()
+
+	assert.Nil(t, debugPolicy.Sampler)
+	assert.Equal(t, 1024, debugPolicy.BodyCaptureLimit)
+	assert.Equal(t, 1024, debugPolicy.DumpBodyLimit)
+	assert.True(t, debugPolicy.DumpEnabled(httptest.NewRequest(http.MethodGet, "http://127.0.0.1/my-fake-url", nil)))
+	assert.NotNil(t, o.Sampler)
+	assert.Equal(t, 10, o.BodyCaptureLimit)
+}
+
+func TestDebugLevelFilter(t *testing.T) {
+	_, store := testing_logger.NewLogger()
+	myLogger := logger.NewLogger(logger_http.DebugLevelFilter(logger.InfoLevel)(store.Handle))
+
+	myLogger.Debug("filtered")
+	myLogger.Debug("debugged", logger.Bool(logger_http.DebugFieldName, true))
+	myLogger.Info("info")
+
+	entries := store.GetEntries()
+	assert.Len(t, entries, 2)
+	assert.Equal(t, "debugged", entries[0].Message)
+	assert.Equal(t, "info", entries[1].Message)
+}
@@ -0,0 +1,237 @@
+package logger_http
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/gol4ng/logger"
+)
+
+// DumpSwitch toggles wire dumps at runtime for every request, a single host or a single route
+// it is safe for concurrent use
+type DumpSwitch struct {
+	mu     sync.RWMutex
+	all    bool
+	hosts  map[string]bool
+	routes map[string]bool
+}
+
+// NewDumpSwitch will create a DumpSwitch with every dump disabled
+func NewDumpSwitch() *DumpSwitch {
+	return &DumpSwitch{hosts: map[string]bool{}, routes: map[string]bool{}}
+}
+
+// EnableAll will toggle the dumps of every request
+func (s *DumpSwitch) EnableAll(enabled bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.all = enabled
+}
+
+// EnableHost will toggle the dumps of the requests sent to or received for the host
+func (s *DumpSwitch) EnableHost(host string, enabled bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if enabled {
+		s.hosts[host] = true
+	} else {
+		delete(s.hosts, host)
+	}
+}
+
+// EnableRoute will toggle the dumps of the requests whose path starts with pathPrefix
+func (s *DumpSwitch) EnableRoute(pathPrefix string, enabled bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if enabled {
+		s.routes[pathPrefix] = true
+	} else {
+		delete(s.routes, pathPrefix)
+	}
+}
+
+// Enabled will return true when the request must be dumped
+func (s *DumpSwitch) Enabled(request *http.Request) bool {
+	if s == nil {
+		return false
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.all {
+		return true
+	}
+	if len(s.hosts) > 0 && (s.hosts[request.Host] || (request.URL != nil && s.hosts[request.URL.Host])) {
+		return true
+	}
+	for pathPrefix := range s.routes {
+		if request.URL != nil && MatchPathPrefix(request.URL.Path, pathPrefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// WithDump will log the wire representation of the requests and responses enabled in the switch
+// bodies are dumped up to bodyLimit bytes, headers and urls are redacted with the configured rules
+func WithDump(dumpSwitch *DumpSwitch, bodyLimit int) Option {
+	return func(o *Options) {
+		o.Dump = dumpSwitch
+		o.DumpBodyLimit = bodyLimit
+	}
+}
+
+// WithDumpLevel will change the level of the dump entries, logger.DebugLevel by default
+func WithDumpLevel(level logger.Level) Option {
+	return func(o *Options) {
+		o.DumpLevel = level
+	}
+}
+
+// DumpEnabled will return true when the request must be dumped
+func (o *Options) DumpEnabled(request *http.Request) bool {
+	return o.Dump.Enabled(request) || (o.Live != nil && o.Live.load().dump.Enabled(request))
+}
+
+// dumpLineBreak separates the dump lines, it is escaped so a dump is logged on a single line
+const dumpLineBreak = `\n`
+
+// DumpRequest will return the wire representation of the request on a single line, line breaks being escaped
+// body is the beginning of the request body, read up to DumpBodyLimit bytes
+func (o *Options) DumpRequest(request *http.Request, body []byte) string {
+	buffer := &strings.Builder{}
+	fmt.Fprintf(buffer, "%s %s %s"+dumpLineBreak, o.Sanitize(request.Method), o.Sanitize(o.SanitizeURL(request.URL)), dumpProto(request.Proto))
+	header := request.Header.Clone()
+	if header == nil {
+		header = http.Header{}
+	}
+	host := request.Host
+	if host == "" && request.URL != nil {
+		host = request.URL.Host
+	}
+	if host != "" {
+		header.Set("Host", host)
+	}
+	o.dumpHeaders(buffer, header, request.TransferEncoding, request.ContentLength)
+	o.dumpBody(buffer, header, body, request.ContentLength)
+	return buffer.String()
+}
+
+// DumpResponse will return the wire representation of the response on a single line, line breaks being escaped
+// body is the beginning of the response body, read up to DumpBodyLimit bytes
+func (o *Options) DumpResponse(response *http.Response, body []byte) string {
+	buffer := &strings.Builder{}
+	status := response.Status
+	if status == "" {
+		status = strconv.Itoa(response.StatusCode) + " " + http.StatusText(response.StatusCode)
+	}
+	fmt.Fprintf(buffer, "%s %s"+dumpLineBreak, dumpProto(response.Proto), o.Sanitize(status))
+	header := response.Header
+	if header == nil {
+		header = http.Header{}
+	}
+	o.dumpHeaders(buffer, header, response.TransferEncoding, response.ContentLength)
+	o.dumpBody(buffer, header, body, response.ContentLength)
+	return buffer.String()
+}
+
+func (o *Options) dumpHeaders(buffer *strings.Builder, header http.Header, transferEncoding []string, contentLength int64) {
+	header = RedactHeader(header, o.RedactedHeaders...)
+	if len(transferEncoding) > 0 && header.Get("Transfer-Encoding") == "" {
+		header.Set("Transfer-Encoding", strings.Join(transferEncoding, ", "))
+	} else if contentLength > 0 && header.Get("Content-Length") == "" {
+		header.Set("Content-Length", strconv.FormatInt(contentLength, 10))
+	}
+	names := make([]string, 0, len(header))
+	for name := range header {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		for _, value := range header[name] {
+			fmt.Fprintf(buffer, "%s: %s"+dumpLineBreak, o.Sanitize(name), o.Sanitize(value))
+		}
+	}
+}
+
+// dumpBody writes the body decoded from its content encoding, chunked bodies are already decoded by net/http
+func (o *Options) dumpBody(buffer *strings.Builder, header http.Header, body []byte, contentLength int64) {
+	if len(body) == 0 {
+		return
+	}
+	buffer.WriteString(dumpLineBreak)
+	truncated := len(body) >= o.DumpBodyLimit && contentLength != int64(len(body))
+
+	encoding := strings.ToLower(header.Get("Content-Encoding"))
+	if encoding != "" && encoding != "identity" {
+		decoded, err := decodeBody(encoding, body)
+		if err != nil {
+			fmt.Fprintf(buffer, "[%d bytes of %s encoded body]", len(body), o.Sanitize(encoding))
+			return
+		}
+		fmt.Fprintf(buffer, "[%s decoded]"+dumpLineBreak, o.Sanitize(encoding))
+		body = decoded
+	}
+	if !utf8.Valid(body) && !(truncated && utf8.Valid(trimIncompleteRune(body))) {
+		fmt.Fprintf(buffer, "[%d bytes of binary body]", len(body))
+		return
+	}
+	// the body line breaks are escaped like the dump ones
+	buffer.WriteString(SanitizeString(string(body), 0))
+	if truncated {
+		buffer.WriteString(TruncatedMarker)
+	}
+}
+
+// decodeBody decodes as much as possible of a possibly truncated compressed body
+func decodeBody(encoding string, body []byte) ([]byte, error) {
+	var reader io.Reader
+	switch encoding {
+	case "gzip", "x-gzip":
+		gzipReader, err := gzip.NewReader(bytes.NewReader(body))
+		if err != nil {
+			return nil, err
+		}
+		reader = gzipReader
+	case "deflate":
+		reader = flate.NewReader(bytes.NewReader(body))
+	default:
+		return nil, fmt.Errorf("unsupported content encoding %s", encoding)
+	}
+	decoded, err := ioutil.ReadAll(reader)
+	if err != nil && err != io.ErrUnexpectedEOF {
+		return nil, err
+	}
+	if err != nil && len(decoded) == 0 {
+		return nil, err
+	}
+	return decoded, nil
+}
+
+func trimIncompleteRune(body []byte) []byte {
+	for i := 1; i < utf8.UTFMax && i <= len(body); i++ {
+		if utf8.RuneStart(body[len(body)-i]) {
+			if !utf8.FullRune(body[len(body)-i:]) {
+				return body[:len(body)-i]
+			}
+			break
+		}
+	}
+	return body
+}
+
+func dumpProto(proto string) string {
+	if proto == "" {
+		return "HTTP/1.1"
+	}
+	return proto
+}
@@ -0,0 +1,108 @@
+package logger_http_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	logger_http "github.com/gol4ng/logger-http"
+)
+
+func TestDumpSwitch(t *testing.T) {
+	var nilSwitch *logger_http.DumpSwitch
+	assert.False(t, nilSwitch.Enabled(httptest.NewRequest(http.MethodGet, "http://127.0.0.1/", nil)))
+
+	dumpSwitch := logger_http.NewDumpSwitch()
+	apiRequest := httptest.NewRequest(http.MethodGet, "http://127.0.0.1/api/users", nil)
+	otherRequest := httptest.NewRequest(http.MethodGet, "http://my-host/other", nil)
+	assert.False(t, dumpSwitch.Enabled(apiRequest))
+
+	dumpSwitch.EnableRoute("/api", true)
+	assert.True(t, dumpSwitch.Enabled(apiRequest))
+	assert.False(t, dumpSwitch.Enabled(otherRequest))
+
+	dumpSwitch.EnableHost("my-host", true)
+	assert.True(t, dumpSwitch.Enabled(otherRequest))
+
+	dumpSwitch.EnableRoute("/api", false)
+	dumpSwitch.EnableHost("my-host", false)
+	assert.False(t, dumpSwitch.Enabled(apiRequest))
+	assert.False(t, dumpSwitch.Enabled(otherRequest))
+
+	dumpSwitch.EnableAll(true)
+	assert.True(t, dumpSwitch.Enabled(apiRequest))
+	assert.True(t, dumpSwitch.Enabled(otherRequest))
+}
+
+func TestOptions_DumpRequest(t *testing.T) {
+	o := logger_http.EvaluateClientOpt(
+		logger_http.WithDump(logger_http.NewDumpSwitch(), 8),
+		logger_http.WithRedactedHeaders("Authorization"),
+		logger_http.WithRedactedQueryParams("token"),
+	)
+
+	request, _ := http.NewRequest(http.MethodPost, "http://127.0.0.1/my-fake-url?token=secret", strings.NewReader("line1\nline2\r\nline3"))
+	request.Header.Set("Authorization", "Bearer secret")
+	assert.Equal(t, `POST http://127.0.0.1/my-fake-url?token=[REDACTED] HTTP/1.1\n`+
+		`Authorization: [REDACTED]\n`+
+		`Content-Length: 18\n`+
+		`Host: 127.0.0.1\n`+
+		`\n`+
+		`line1\nli`+logger_http.TruncatedMarker,
+		o.DumpRequest(request, []byte("line1\nli")),
+	)
+
+	chunked := httptest.NewRequest(http.MethodPost, "/my-fake-url", strings.NewReader("my\r\nbody"))
+	chunked.ContentLength = -1
+	chunked.TransferEncoding = []string{"chunked"}
+	assert.Equal(t, `POST /my-fake-url HTTP/1.1\n`+
+		`Host: example.com\n`+
+		`Transfer-Encoding: chunked\n`+
+		`\n`+
+		`my\r\nbody`,
+		logger_http.EvaluateClientOpt(logger_http.WithDump(logger_http.NewDumpSwitch(), 1024)).DumpRequest(chunked, []byte("my\r\nbody")),
+	)
+
+	forged := []byte("my body\nlevel=error msg=forged")
+	forgedRequest := httptest.NewRequest(http.MethodPost, "/my-fake-url", bytes.NewReader(forged))
+	dump := logger_http.EvaluateClientOpt(logger_http.WithDump(logger_http.NewDumpSwitch(), 1024)).DumpRequest(forgedRequest, forged)
+	assert.NotContains(t, dump, "\n")
+	assert.True(t, strings.HasSuffix(dump, `\n\nmy body\nlevel=error msg=forged`))
+}
+
+func TestOptions_DumpResponse(t *testing.T) {
+	o := logger_http.EvaluateClientOpt(logger_http.WithDump(logger_http.NewDumpSwitch(), 1024))
+
+	compressed := &bytes.Buffer{}
+	writer := gzip.NewWriter(compressed)
+	writer.Write([]byte(`{"name":"my response"}`))
+	writer.Close()
+
+	response := &http.Response{
+		Proto:         "HTTP/2.0",
+		Status:        "200 OK",
+		StatusCode:    http.StatusOK,
+		Header:        http.Header{"Content-Encoding": {"gzip"}},
+		ContentLength: int64(compressed.Len()),
+	}
+	assert.Equal(t, `HTTP/2.0 200 OK\n`+
+		`Content-Encoding: gzip\n`+
+		`Content-Length: `+strconv.Itoa(compressed.Len())+`\n`+
+		`\n`+
+		`[gzip decoded]\n`+
+		`{"name":"my response"}`,
+		o.DumpResponse(response, compressed.Bytes()),
+	)
+
+	truncated := o.DumpResponse(response, compressed.Bytes()[:compressed.Len()-8])
+	assert.Contains(t, truncated, `[gzip decoded]\n`)
+
+	binary := &http.Response{StatusCode: http.StatusNotFound, Header: http.Header{}}
+	assert.Equal(t, `HTTP/1.1 404 Not Found\n\n[3 bytes of binary body]`, o.DumpResponse(binary, []byte{0xff, 0xfe, 0xfd}))
+}
@@ -0,0 +1,297 @@
+package logger_http
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gol4ng/logger"
+)
+
+// OverflowPolicy tells the AsyncEmitter what to do when its queue is full
+type OverflowPolicy int
+
+const (
+	// DropOnFull drops the entry and counts it, the request goroutine never waits
+	DropOnFull OverflowPolicy = iota
+	// BlockOnFull waits for a free slot in the queue
+	BlockOnFull
+)
+
+// AsyncEmitter emits the entries of middleware.Logger and tripperware.Logger from worker goroutines
+// so a slow log handler does not add to the response latency
+// eg:
+//
+//	emitter := logger_http.NewAsyncEmitter(1024, 2, logger_http.DropOnFull)
+//	emitter.ReportDropped(l, time.Minute)
+//	defer emitter.Close(context.Background())
+//	middleware.Logger(l, logger_http.WithAsyncEmitter(emitter))
+type AsyncEmitter struct {
+	queue   chan asyncEntry
+	policy  OverflowPolicy
+	workers sync.WaitGroup
+	// senders tracks the emit calls between the closed check and the queue send
+	senders sync.WaitGroup
+
+	mu      sync.RWMutex
+	closed  bool
+	done    chan struct{}
+	stopped chan struct{}
+
+	pendingMu sync.Mutex
+	pending   int
+	idle      chan struct{}
+
+	dropped uint64
+	panics  uint64
+}
+
+type asyncEntry struct {
+	logger  logger.LoggerInterface
+	message string
+	level   logger.Level
+	fields  []logger.Field
+}
+
+// NewAsyncEmitter will create an AsyncEmitter with a queue of queueSize entries consumed by workers goroutines
+func NewAsyncEmitter(queueSize int, workers int, policy OverflowPolicy) *AsyncEmitter {
+	if workers < 1 {
+		workers = 1
+	}
+	e := &AsyncEmitter{
+		queue:   make(chan asyncEntry, queueSize),
+		policy:  policy,
+		done:    make(chan struct{}),
+		stopped: make(chan struct{}),
+	}
+	e.workers.Add(workers)
+	for i := 0; i < workers; i++ {
+		go e.work()
+	}
+	return e
+}
+
+// WithAsyncEmitter will emit the entries through the emitter
+func WithAsyncEmitter(emitter *AsyncEmitter) Option {
+	return func(o *Options) {
+		o.Emitter = emitter
+	}
+}
+
+// Logger will decorate the logger so its entries are emitted asynchronously
+// entries are emitted synchronously once the emitter is closed
+func (e *AsyncEmitter) Logger(l logger.LoggerInterface) logger.LoggerInterface {
+	if e == nil || l == nil {
+		return l
+	}
+	return &asyncLogger{emitter: e, logger: l}
+}
+
+// Dropped returns the number of entries dropped since the creation of the emitter
+func (e *AsyncEmitter) Dropped() uint64 {
+	return atomic.LoadUint64(&e.dropped)
+}
+
+// Panics returns the number of handler panics recovered by the workers since the creation of the emitter
+func (e *AsyncEmitter) Panics() uint64 {
+	return atomic.LoadUint64(&e.panics)
+}
+
+// ReportDropped will log a warning every interval when entries were dropped or handler panics were recovered since the previous report
+// the reporting stops when the emitter is closed
+func (e *AsyncEmitter) ReportDropped(log logger.LoggerInterface, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		reportedDropped := uint64(0)
+		reportedPanics := uint64(0)
+		for {
+			select {
+			case <-e.done:
+				return
+			case <-ticker.C:
+				dropped := e.Dropped()
+				if dropped > reportedDropped {
+					log.Warning("logger-http async emitter dropped entries",
+						logger.Uint64("dropped", dropped-reportedDropped),
+						logger.Uint64("dropped_total", dropped),
+					)
+					reportedDropped = dropped
+				}
+				panics := e.Panics()
+				if panics > reportedPanics {
+					log.Warning("logger-http async emitter recovered handler panics",
+						logger.Uint64("panics", panics-reportedPanics),
+						logger.Uint64("panics_total", panics),
+					)
+					reportedPanics = panics
+				}
+			}
+		}
+	}()
+}
+
+// Flush will wait until every queued entry is emitted or the go-context is done
+func (e *AsyncEmitter) Flush(ctx context.Context) error {
+	e.pendingMu.Lock()
+	if e.pending == 0 {
+		e.pendingMu.Unlock()
+		return nil
+	}
+	if e.idle == nil {
+		e.idle = make(chan struct{})
+	}
+	idle := e.idle
+	e.pendingMu.Unlock()
+
+	select {
+	case <-idle:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Close will stop accepting entries and wait until the queued ones are emitted or the go-context is done
+func (e *AsyncEmitter) Close(ctx context.Context) error {
+	e.mu.Lock()
+	if !e.closed {
+		e.closed = true
+		// unblocks the BlockOnFull senders, they emit synchronously
+		close(e.done)
+		go func() {
+			e.senders.Wait()
+			close(e.queue)
+			e.workers.Wait()
+			close(e.stopped)
+		}()
+	}
+	e.mu.Unlock()
+
+	select {
+	case <-e.stopped:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (e *AsyncEmitter) emit(entry asyncEntry) {
+	e.mu.RLock()
+	if e.closed {
+		e.mu.RUnlock()
+		entry.logger.Log(entry.message, entry.level, entry.fields...)
+		return
+	}
+	e.senders.Add(1)
+	e.mu.RUnlock()
+	defer e.senders.Done()
+
+	e.addPending(1)
+	if e.policy == BlockOnFull {
+		select {
+		case e.queue <- entry:
+		case <-e.done:
+			e.addPending(-1)
+			entry.logger.Log(entry.message, entry.level, entry.fields...)
+		}
+		return
+	}
+	select {
+	case e.queue <- entry:
+	default:
+		e.addPending(-1)
+		atomic.AddUint64(&e.dropped, 1)
+	}
+}
+
+func (e *AsyncEmitter) addPending(delta int) {
+	e.pendingMu.Lock()
+	defer e.pendingMu.Unlock()
+	e.pending += delta
+	if e.pending == 0 && e.idle != nil {
+		close(e.idle)
+		e.idle = nil
+	}
+}
+
+func (e *AsyncEmitter) work() {
+	defer e.workers.Done()
+	for entry := range e.queue {
+		e.handle(entry)
+	}
+}
+
+func (e *AsyncEmitter) handle(entry asyncEntry) {
+	defer e.addPending(-1)
+	defer func() {
+		// a panicking handler must not stop the worker, the panics are counted and reported by ReportDropped
+		if recover() != nil {
+			atomic.AddUint64(&e.panics, 1)
+		}
+	}()
+	entry.logger.Log(entry.message, entry.level, entry.fields...)
+}
+
+type asyncLogger struct {
+	emitter *AsyncEmitter
+	logger  logger.LoggerInterface
+}
+
+func (l *asyncLogger) Log(message string, level logger.Level, fields ...logger.Field) {
+	l.emitter.emit(asyncEntry{logger: l.logger, message: message, level: level, fields: fields})
+}
+
+func (l *asyncLogger) Enabled(level logger.Level) bool {
+	return levelEnabled(l.logger, level)
+}
+
+// Wrap will wrap the underlying logger when it is a logger.WrappableLoggerInterface
+func (l *asyncLogger) Wrap(middlewares ...logger.MiddlewareInterface) logger.LoggerInterface {
+	if wrappableLogger, ok := l.logger.(logger.WrappableLoggerInterface); ok {
+		wrappableLogger.Wrap(middlewares...)
+	}
+	return l
+}
+
+// WrapNew will return an asyncLogger of the same emitter with a wrapped underlying logger
+func (l *asyncLogger) WrapNew(middlewares ...logger.MiddlewareInterface) logger.LoggerInterface {
+	wrapped := l.logger
+	if wrappableLogger, ok := l.logger.(logger.WrappableLoggerInterface); ok {
+		wrapped = wrappableLogger.WrapNew(middlewares...)
+	}
+	return &asyncLogger{emitter: l.emitter, logger: wrapped}
+}
+
+func (l *asyncLogger) Debug(message string, fields ...logger.Field) {
+	l.Log(message, logger.DebugLevel, fields...)
+}
+
+func (l *asyncLogger) Info(message string, fields ...logger.Field) {
+	l.Log(message, logger.InfoLevel, fields...)
+}
+
+func (l *asyncLogger) Notice(message string, fields ...logger.Field) {
+	l.Log(message, logger.NoticeLevel, fields...)
+}
+
+func (l *asyncLogger) Warning(message string, fields ...logger.Field) {
+	l.Log(message, logger.WarningLevel, fields...)
+}
+
+func (l *asyncLogger) Error(message string, fields ...logger.Field) {
+	l.Log(message, logger.ErrorLevel, fields...)
+}
+
+func (l *asyncLogger) Critical(message string, fields ...logger.Field) {
+	l.Log(message, logger.CriticalLevel, fields...)
+}
+
+func (l *asyncLogger) Alert(message string, fields ...logger.Field) {
+	l.Log(message, logger.AlertLevel, fields...)
+}
+
+func (l *asyncLogger) Emergency(message string, fields ...logger.Field) {
+	l.Log(message, logger.EmergencyLevel, fields...)
+}
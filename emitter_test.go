@@ -0,0 +1,151 @@
+package logger_http_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/gol4ng/logger"
+	"github.com/gol4ng/logger/middleware"
+	testing_logger "github.com/gol4ng/logger/testing"
+	"github.com/stretchr/testify/assert"
+
+	logger_http "github.com/gol4ng/logger-http"
+)
+
+func TestAsyncEmitter(t *testing.T) {
+	myLogger, store := testing_logger.NewLogger()
+	emitter := logger_http.NewAsyncEmitter(10, 1, logger_http.BlockOnFull)
+
+	var nilEmitter *logger_http.AsyncEmitter
+	assert.Same(t, myLogger, nilEmitter.Logger(myLogger))
+
+	asyncLogger := emitter.Logger(myLogger)
+	asyncLogger.Info("first", logger.String("key", "value"))
+	asyncLogger.Error("second")
+	assert.Nil(t, emitter.Flush(context.Background()))
+
+	entries := store.GetEntries()
+	assert.Len(t, entries, 2)
+	assert.Equal(t, "first", entries[0].Message)
+	assert.Equal(t, logger.InfoLevel, entries[0].Level)
+	assert.Equal(t, "value", (*entries[0].Context)["key"].Value)
+	assert.Equal(t, logger.ErrorLevel, entries[1].Level)
+
+	assert.Nil(t, emitter.Close(context.Background()))
+	asyncLogger.Warning("after close")
+	assert.Len(t, store.GetEntries(), 3)
+	assert.Nil(t, emitter.Close(context.Background()))
+}
+
+func TestAsyncEmitter_DropOnFull(t *testing.T) {
+	release := make(chan struct{})
+	_, store := testing_logger.NewLogger()
+	blockingLogger := logger.NewLogger(func(entry logger.Entry) error {
+		<-release
+		return store.Handle(entry)
+	})
+	reportLogger, reportStore := testing_logger.NewLogger()
+
+	emitter := logger_http.NewAsyncEmitter(1, 1, logger_http.DropOnFull)
+	emitter.ReportDropped(reportLogger, 5*time.Millisecond)
+	asyncLogger := emitter.Logger(blockingLogger)
+
+	asyncLogger.Info("handled by the worker")
+	logged := uint64(1)
+	assert.Eventually(t, func() bool {
+		asyncLogger.Info("queued or dropped")
+		logged++
+		return emitter.Dropped() >= 2
+	}, time.Second, time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	assert.Equal(t, context.DeadlineExceeded, emitter.Flush(ctx))
+
+	assert.Eventually(t, func() bool {
+		return len(reportStore.GetEntries()) > 0
+	}, time.Second, time.Millisecond)
+	report := reportStore.GetEntries()[0]
+	assert.Equal(t, logger.WarningLevel, report.Level)
+	assert.Equal(t, "logger-http async emitter dropped entries", report.Message)
+
+	close(release)
+	assert.Nil(t, emitter.Close(context.Background()))
+	assert.Equal(t, logged-emitter.Dropped(), uint64(len(store.GetEntries())))
+}
+
+func TestAsyncEmitter_HandlerPanic(t *testing.T) {
+	panicLogger := logger.NewLogger(func(entry logger.Entry) error {
+		panic("my handler panic")
+	})
+	reportLogger, reportStore := testing_logger.NewLogger()
+	emitter := logger_http.NewAsyncEmitter(10, 1, logger_http.BlockOnFull)
+	emitter.ReportDropped(reportLogger, time.Millisecond)
+
+	emitter.Logger(panicLogger).Info("panic")
+	emitter.Logger(panicLogger).Info("panic again")
+	assert.Nil(t, emitter.Flush(context.Background()))
+	assert.Equal(t, uint64(2), emitter.Panics())
+
+	assert.Eventually(t, func() bool {
+		return len(reportStore.GetEntries()) > 0
+	}, time.Second, time.Millisecond)
+	report := reportStore.GetEntries()[0]
+	assert.Equal(t, "logger-http async emitter recovered handler panics", report.Message)
+	assert.Equal(t, uint64(2), (*report.Context)["panics_total"].Value)
+	assert.Nil(t, emitter.Close(context.Background()))
+}
+
+func TestAsyncEmitter_CloseWithBlockedSender(t *testing.T) {
+	release := make(chan struct{})
+	_, store := testing_logger.NewLogger()
+	blockingLogger := logger.NewLogger(func(entry logger.Entry) error {
+		<-release
+		return store.Handle(entry)
+	})
+	emitter := logger_http.NewAsyncEmitter(1, 1, logger_http.BlockOnFull)
+	asyncLogger := emitter.Logger(blockingLogger)
+
+	asyncLogger.Info("handled by the worker")
+	asyncLogger.Info("queued")
+	sent := make(chan struct{})
+	go func() {
+		// the queue is full, the send blocks until the emitter is closed
+		asyncLogger.Info("blocked")
+		close(sent)
+	}()
+
+	closed := make(chan error)
+	go func() {
+		closed <- emitter.Close(context.Background())
+	}()
+	// the closing emitter must keep accepting entries, they are emitted synchronously
+	emitted := make(chan struct{})
+	go func() {
+		asyncLogger.Info("after close")
+		close(emitted)
+	}()
+	close(release)
+
+	assert.Nil(t, <-closed)
+	<-sent
+	<-emitted
+	assert.Len(t, store.GetEntries(), 4)
+}
+
+func TestAsyncEmitter_WrapNew(t *testing.T) {
+	myLogger, store := testing_logger.NewLogger()
+	emitter := logger_http.NewAsyncEmitter(10, 1, logger_http.BlockOnFull)
+
+	asyncLogger, ok := emitter.Logger(myLogger).(logger.WrappableLoggerInterface)
+	assert.True(t, ok)
+	asyncLogger.WrapNew(middleware.Context(logger.NewContext().Add("my_key", "my_value"))).Info("wrapped")
+	asyncLogger.Info("not wrapped")
+	assert.Nil(t, emitter.Close(context.Background()))
+
+	entries := store.GetEntries()
+	assert.Len(t, entries, 2)
+	assert.Equal(t, "my_value", (*entries[0].Context)["my_key"].Value)
+	assert.NotContains(t, *entries[1].Context, "my_key")
+}
@@ -0,0 +1,90 @@
+package logger_http
+
+import (
+	"log"
+	"regexp"
+	"strings"
+
+	"github.com/gol4ng/logger"
+)
+
+// ServerErrorKindFieldName is the field holding the kind of a net/http server error
+const ServerErrorKindFieldName = "http_error_kind"
+
+type serverErrorPattern struct {
+	kind    string
+	level   logger.Level
+	pattern *regexp.Regexp
+}
+
+// serverErrorPatterns matches the messages net/http and golang.org/x/net/http2 write into http.Server.ErrorLog
+// the named groups are added as fields, see serverErrorFieldNames
+var serverErrorPatterns = []serverErrorPattern{
+	{kind: "tls_handshake", level: logger.NoticeLevel, pattern: regexp.MustCompile(`^http: TLS handshake error from (?P<remote_addr>\S+): (?P<error>.*)$`)},
+	{kind: "panic", level: logger.CriticalLevel, pattern: regexp.MustCompile(`(?s)^http: panic serving (?P<remote_addr>\S+): (?P<panic>.*)$`)},
+	{kind: "accept", level: logger.ErrorLevel, pattern: regexp.MustCompile(`^http: Accept error: (?P<error>.*); retrying in (?P<retry>\S+)$`)},
+	{kind: "response_misuse", level: logger.WarningLevel, pattern: regexp.MustCompile(`^http: (?:superfluous response\.WriteHeader call|multiple response\.WriteHeader calls|response\.WriteHeader on hijacked connection|response\.Write on hijacked connection)(?: from (?P<caller>.*))?$`)},
+	{kind: "response_misuse", level: logger.WarningLevel, pattern: regexp.MustCompile(`^http: invalid WriteHeader code (?P<error>.*)$`)},
+	{kind: "query_semicolon", level: logger.WarningLevel, pattern: regexp.MustCompile(`^http: URL query contains semicolon`)},
+	{kind: "proxy", level: logger.ErrorLevel, pattern: regexp.MustCompile(`^http: proxy error: (?P<error>.*)$`)},
+	{kind: "http2_preface", level: logger.NoticeLevel, pattern: regexp.MustCompile(`^http2: server: error reading preface from client (?P<remote_addr>\S+): (?P<error>.*)$`)},
+	{kind: "http2", level: logger.NoticeLevel, pattern: regexp.MustCompile(`^http2: (?P<error>.*)$`)},
+}
+
+var serverErrorFieldNames = map[string]string{
+	"remote_addr": "http_remote_addr",
+	"error":       "http_error_message",
+	"panic":       "http_panic",
+	"caller":      "http_caller",
+	"retry":       "http_retry_delay",
+}
+
+// goroutineStackHeader matches the start of the goroutine stack following a handler panic
+var goroutineStackHeader = regexp.MustCompile(`\ngoroutine \d+ \[`)
+
+// NewServerErrorLog will create a *log.Logger for http.Server.ErrorLog writing into the given logger
+// known net/http messages are parsed into fields (http_error_kind, http_remote_addr...) and logged with a matching level
+// unknown messages are logged as errors with the "unknown" kind
+// eg: server := &http.Server{Handler: handler, ErrorLog: logger_http.NewServerErrorLog(myLogger)}
+func NewServerErrorLog(l logger.LoggerInterface) *log.Logger {
+	return log.New(&serverErrorWriter{logger: l}, "", 0)
+}
+
+type serverErrorWriter struct {
+	logger logger.LoggerInterface
+}
+
+func (w *serverErrorWriter) Write(p []byte) (int, error) {
+	message, level, fields := parseServerError(string(p))
+	w.logger.Log(message, level, fields...)
+	return len(p), nil
+}
+
+func parseServerError(message string) (string, logger.Level, []logger.Field) {
+	message = strings.TrimRight(message, "\n")
+	stack := ""
+	// handler panics are followed by the goroutine stack, the panic value before it may hold line breaks too
+	if indexes := goroutineStackHeader.FindAllStringIndex(message, -1); indexes != nil {
+		index := indexes[len(indexes)-1][0]
+		message, stack = message[:index], message[index+1:]
+	}
+
+	fields := []logger.Field{logger.String("http_kind", "server")}
+	if stack != "" {
+		fields = append(fields, logger.String("http_stack", SanitizeString(stack, 0)))
+	}
+	for _, serverError := range serverErrorPatterns {
+		matches := serverError.pattern.FindStringSubmatch(message)
+		if matches == nil {
+			continue
+		}
+		fields = append(fields, logger.String(ServerErrorKindFieldName, serverError.kind))
+		for i, name := range serverError.pattern.SubexpNames() {
+			if fieldName, ok := serverErrorFieldNames[name]; ok && matches[i] != "" {
+				fields = append(fields, logger.String(fieldName, SanitizeString(matches[i], 0)))
+			}
+		}
+		return SanitizeString(message, 0), serverError.level, fields
+	}
+	return SanitizeString(message, 0), logger.ErrorLevel, append(fields, logger.String(ServerErrorKindFieldName, "unknown"))
+}
@@ -0,0 +1,162 @@
+package logger_http_test
+
+import (
+	"bufio"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gol4ng/logger"
+	testing_logger "github.com/gol4ng/logger/testing"
+	"github.com/stretchr/testify/assert"
+
+	logger_http "github.com/gol4ng/logger-http"
+)
+
+func TestNewServerErrorLog(t *testing.T) {
+	tests := []struct {
+		message        string
+		expectedLevel  logger.Level
+		expectedFields map[string]interface{}
+	}{
+		{
+			message:       "http: TLS handshake error from 10.0.0.1:51234: EOF",
+			expectedLevel: logger.NoticeLevel,
+			expectedFields: map[string]interface{}{
+				"http_error_kind":    "tls_handshake",
+				"http_remote_addr":   "10.0.0.1:51234",
+				"http_error_message": "EOF",
+			},
+		},
+		{
+			message:       "http: Accept error: accept tcp [::]:8080: accept4: too many open files; retrying in 5ms",
+			expectedLevel: logger.ErrorLevel,
+			expectedFields: map[string]interface{}{
+				"http_error_kind":    "accept",
+				"http_error_message": "accept tcp [::]:8080: accept4: too many open files",
+				"http_retry_delay":   "5ms",
+			},
+		},
+		{
+			message:       "http: superfluous response.WriteHeader call from main.handler (main.go:42)",
+			expectedLevel: logger.WarningLevel,
+			expectedFields: map[string]interface{}{
+				"http_error_kind": "response_misuse",
+				"http_caller":     "main.handler (main.go:42)",
+			},
+		},
+		{
+			message:       "http: multiple response.WriteHeader calls",
+			expectedLevel: logger.WarningLevel,
+			expectedFields: map[string]interface{}{
+				"http_error_kind": "response_misuse",
+			},
+		},
+		{
+			message:       "http: invalid WriteHeader code 42",
+			expectedLevel: logger.WarningLevel,
+			expectedFields: map[string]interface{}{
+				"http_error_kind":    "response_misuse",
+				"http_error_message": "42",
+			},
+		},
+		{
+			message:       "http: URL query contains semicolon, which is no longer a supported separator; parts of the query may be stripped when parsed; see golang.org/issue/25192",
+			expectedLevel: logger.WarningLevel,
+			expectedFields: map[string]interface{}{
+				"http_error_kind": "query_semicolon",
+			},
+		},
+		{
+			message:       "http: proxy error: dial tcp 10.0.0.2:80: connect: connection refused",
+			expectedLevel: logger.ErrorLevel,
+			expectedFields: map[string]interface{}{
+				"http_error_kind":    "proxy",
+				"http_error_message": "dial tcp 10.0.0.2:80: connect: connection refused",
+			},
+		},
+		{
+			message:       "http2: server: error reading preface from client 10.0.0.1:51234: bogus greeting",
+			expectedLevel: logger.NoticeLevel,
+			expectedFields: map[string]interface{}{
+				"http_error_kind":    "http2_preface",
+				"http_remote_addr":   "10.0.0.1:51234",
+				"http_error_message": "bogus greeting",
+			},
+		},
+		{
+			message:       "http2: received GOAWAY",
+			expectedLevel: logger.NoticeLevel,
+			expectedFields: map[string]interface{}{
+				"http_error_kind":    "http2",
+				"http_error_message": "received GOAWAY",
+			},
+		},
+		{
+			message:       "something unexpected\x1b[31m",
+			expectedLevel: logger.ErrorLevel,
+			expectedFields: map[string]interface{}{
+				"http_error_kind": "unknown",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.message, func(t *testing.T) {
+			myLogger, store := testing_logger.NewLogger()
+			logger_http.NewServerErrorLog(myLogger).Print(tt.message)
+
+			entries := store.GetEntries()
+			assert.Len(t, entries, 1)
+			assert.Equal(t, tt.expectedLevel, entries[0].Level)
+			assert.Equal(t, logger_http.SanitizeString(tt.message, 0), entries[0].Message)
+			assert.Equal(t, "server", (*entries[0].Context)["http_kind"].Value)
+			for name, value := range tt.expectedFields {
+				assert.Equal(t, value, (*entries[0].Context)[name].Value, name)
+			}
+		})
+	}
+}
+
+func TestNewServerErrorLog_Panic(t *testing.T) {
+	myLogger, store := testing_logger.NewLogger()
+	server := &http.Server{
+		Handler: http.HandlerFunc(func(writer http.ResponseWriter, req *http.Request) {
+			panic("my handler panic")
+		}),
+		ErrorLog: logger_http.NewServerErrorLog(myLogger),
+	}
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.Nil(t, err)
+	go server.Serve(listener)
+	defer server.Close()
+
+	connection, err := net.Dial("tcp", listener.Addr().String())
+	assert.Nil(t, err)
+	defer connection.Close()
+	connection.Write([]byte("GET / HTTP/1.1\r\nHost: example.com\r\n\r\n"))
+	bufio.NewReader(connection).ReadString('\n')
+
+	assert.Eventually(t, func() bool { return len(store.GetEntries()) == 1 }, time.Second, time.Millisecond)
+	entry := store.GetEntries()[0]
+	assert.Equal(t, logger.CriticalLevel, entry.Level)
+	assert.Equal(t, "panic", (*entry.Context)["http_error_kind"].Value)
+	assert.Equal(t, connection.LocalAddr().String(), (*entry.Context)["http_remote_addr"].Value)
+	assert.Equal(t, "my handler panic", (*entry.Context)["http_panic"].Value)
+	assert.True(t, strings.HasPrefix((*entry.Context)["http_stack"].Value.(string), "goroutine "))
+	assert.NotContains(t, (*entry.Context)["http_stack"].Value, "\n")
+	assert.NotContains(t, entry.Message, "\n")
+}
+
+func TestNewServerErrorLog_PanicWithLineBreaks(t *testing.T) {
+	myLogger, store := testing_logger.NewLogger()
+	logger_http.NewServerErrorLog(myLogger).Print("http: panic serving 127.0.0.1:1234: my panic\nlevel=error msg=forged\ngoroutine 7 [running]:\nmain.handler()\n\t/main.go:12 +0x1d\n")
+
+	entries := store.GetEntries()
+	assert.Len(t, entries, 1)
+	assert.Equal(t, logger.CriticalLevel, entries[0].Level)
+	assert.Equal(t, `http: panic serving 127.0.0.1:1234: my panic\nlevel=error msg=forged`, entries[0].Message)
+	assert.Equal(t, `my panic\nlevel=error msg=forged`, (*entries[0].Context)["http_panic"].Value)
+	assert.Equal(t, `goroutine 7 [running]:\nmain.handler()\n\t/main.go:12 +0x1d`, (*entries[0].Context)["http_stack"].Value)
+}
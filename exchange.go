@@ -0,0 +1,146 @@
+package logger_http
+
+import (
+	"net/http"
+	"strconv"
+	"sync/atomic"
+	"time"
+)
+
+// Exchange is the typed record of an http exchange handled by middleware.Logger or tripperware.Logger
+type Exchange struct {
+	// bytesWritten is first to stay 64-bit aligned for atomic operations
+	bytesWritten int64
+
+	// Kind is "server" or "client"
+	Kind   string
+	Method string
+	// URL is sanitized with the configured redaction rules
+	URL string
+	// Route is the path prefix of the matching route policy, empty when none matches
+	Route string
+	// Peer is the client ip for a server exchange and the remote host for a client exchange
+	Peer          string
+	CorrelationId string
+
+	StartTime time.Time
+	Duration  time.Duration
+
+	StatusCode     int
+	Status         string
+	RequestLength  int64
+	ResponseLength int64
+	Error          error
+	Panic          interface{}
+
+	Request  *http.Request
+	Response *http.Response
+}
+
+// AddBytesWritten will count n more response bytes, it is safe for concurrent use
+func (e *Exchange) AddBytesWritten(n int) {
+	atomic.AddInt64(&e.bytesWritten, int64(n))
+}
+
+// BytesWritten will return the response bytes written so far
+// it is only counted by middleware.Logger when a Registry is configured
+func (e *Exchange) BytesWritten() int64 {
+	return atomic.LoadInt64(&e.bytesWritten)
+}
+
+// Observer is notified of every exchange that is not filtered
+// the same *Exchange is given to every callback of an exchange
+type Observer interface {
+	OnStart(exchange *Exchange)
+	OnEnd(exchange *Exchange)
+	OnPanic(exchange *Exchange)
+}
+
+// Observers notifies every observer in order
+type Observers []Observer
+
+func (o Observers) OnStart(exchange *Exchange) {
+	for _, observer := range o {
+		observer.OnStart(exchange)
+	}
+}
+
+func (o Observers) OnEnd(exchange *Exchange) {
+	for _, observer := range o {
+		observer.OnEnd(exchange)
+	}
+}
+
+func (o Observers) OnPanic(exchange *Exchange) {
+	for _, observer := range o {
+		observer.OnPanic(exchange)
+	}
+}
+
+// ObserverFuncs is an Observer built from optional functions
+type ObserverFuncs struct {
+	Start func(exchange *Exchange)
+	End   func(exchange *Exchange)
+	Panic func(exchange *Exchange)
+}
+
+func (o ObserverFuncs) OnStart(exchange *Exchange) {
+	if o.Start != nil {
+		o.Start(exchange)
+	}
+}
+
+func (o ObserverFuncs) OnEnd(exchange *Exchange) {
+	if o.End != nil {
+		o.End(exchange)
+	}
+}
+
+func (o ObserverFuncs) OnPanic(exchange *Exchange) {
+	if o.Panic != nil {
+		o.Panic(exchange)
+	}
+}
+
+// WithObservers will notify the given observers of every exchange
+func WithObservers(observers ...Observer) Option {
+	return func(o *Options) {
+		o.Observers = append(append(Observers{}, o.Observers...), observers...)
+	}
+}
+
+// WithCorrelationIdHeader customizes the header used to fill Exchange.CorrelationId
+func WithCorrelationIdHeader(headerName string) Option {
+	return func(o *Options) {
+		o.CorrelationIdHeader = headerName
+	}
+}
+
+// NewExchange will create the exchange record of the request
+func (o *Options) NewExchange(kind string, request *http.Request, startTime time.Time) *Exchange {
+	exchange := &Exchange{
+		Kind:          kind,
+		Method:        o.Sanitize(request.Method),
+		URL:           o.Sanitize(o.SanitizeURL(request.URL)),
+		Route:         o.route,
+		CorrelationId: request.Header.Get(o.CorrelationIdHeader),
+		StartTime:     startTime,
+		RequestLength: request.ContentLength,
+		Request:       request,
+	}
+	if kind == "server" {
+		exchange.Peer = clientIP(request)
+	} else if request.URL != nil {
+		exchange.Peer = request.URL.Host
+	}
+	return exchange
+}
+
+// Message will return the log message of the completed exchange
+// "http server GET /path [status_code:200, duration:1ms, content_length:42]"
+func (e *Exchange) Message() string {
+	return "http " + e.Kind + " " + e.Method + " " + e.URL +
+		" [status_code:" + strconv.Itoa(e.StatusCode) +
+		", duration:" + e.Duration.String() +
+		", content_length:" + strconv.FormatInt(e.ResponseLength, 10) + "]"
+}
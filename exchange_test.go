@@ -0,0 +1,79 @@
+package logger_http_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	logger_http "github.com/gol4ng/logger-http"
+)
+
+func TestObservers(t *testing.T) {
+	var calls []string
+	observer := func(name string) logger_http.Observer {
+		return logger_http.ObserverFuncs{
+			Start: func(exchange *logger_http.Exchange) { calls = append(calls, name+" start") },
+			End:   func(exchange *logger_http.Exchange) { calls = append(calls, name+" end") },
+			Panic: func(exchange *logger_http.Exchange) { calls = append(calls, name+" panic") },
+		}
+	}
+
+	o := logger_http.EvaluateServerOpt(
+		logger_http.WithObservers(observer("first")),
+		logger_http.WithObservers(observer("second"), logger_http.ObserverFuncs{}),
+	)
+
+	exchange := &logger_http.Exchange{}
+	o.Observers.OnStart(exchange)
+	o.Observers.OnEnd(exchange)
+	o.Observers.OnPanic(exchange)
+
+	assert.Equal(t, []string{
+		"first start", "second start",
+		"first end", "second end",
+		"first panic", "second panic",
+	}, calls)
+}
+
+func TestOptions_NewExchange(t *testing.T) {
+	request := httptest.NewRequest(http.MethodPost, "http://127.0.0.1/api/my-fake-url?token=secret", nil)
+	request.RemoteAddr = "10.0.0.1:1234"
+	request.ContentLength = 12
+	request.Header.Set("Correlation-Id", "my-correlation-id")
+	startTime := time.Now()
+
+	o := logger_http.EvaluateServerOpt(
+		logger_http.WithRedactedQueryParams("token"),
+		logger_http.WithRoutePolicy("/api"),
+	)
+
+	exchange := o.Policy(request).NewExchange("server", request, startTime)
+	assert.Equal(t, "server", exchange.Kind)
+	assert.Equal(t, "POST", exchange.Method)
+	assert.Equal(t, "http://127.0.0.1/api/my-fake-url?token=[REDACTED]", exchange.URL)
+	assert.Equal(t, "/api", exchange.Route)
+	assert.Equal(t, "10.0.0.1", exchange.Peer)
+	assert.Equal(t, "my-correlation-id", exchange.CorrelationId)
+	assert.Equal(t, startTime, exchange.StartTime)
+	assert.Equal(t, int64(12), exchange.RequestLength)
+	assert.Equal(t, request, exchange.Request)
+
+	clientExchange := o.NewExchange("client", request, startTime)
+	assert.Equal(t, "", clientExchange.Route)
+	assert.Equal(t, "127.0.0.1", clientExchange.Peer)
+}
+
+func TestExchange_Message(t *testing.T) {
+	exchange := &logger_http.Exchange{
+		Kind:           "client",
+		Method:         "GET",
+		URL:            "http://127.0.0.1/my-fake-url",
+		StatusCode:     404,
+		Duration:       1500 * time.Millisecond,
+		ResponseLength: 42,
+	}
+	assert.Equal(t, "http client GET http://127.0.0.1/my-fake-url [status_code:404, duration:1.5s, content_length:42]", exchange.Message())
+}
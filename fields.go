@@ -0,0 +1,93 @@
+package logger_http
+
+import (
+	"github.com/gol4ng/logger"
+)
+
+// OTelFieldNames renames the fields after the OpenTelemetry http semantic conventions
+var OTelFieldNames = map[string]string{
+	"http_method":          "http.request.method",
+	"http_url":             "url.full",
+	"http_header":          "http.request.header",
+	"http_status_code":     "http.response.status_code",
+	"http_response_length": "http.response.body.size",
+	"http_client_ip":       "client.address",
+	"http_user_agent":      "user_agent.original",
+	"http_host":            "server.address",
+	"http_error_message":   "error.message",
+}
+
+// WithFieldNames will rename the logged fields, names maps the default field name to the new one
+// eg: WithFieldNames(OTelFieldNames)
+func WithFieldNames(names map[string]string) Option {
+	return func(o *Options) {
+		fieldNames := make(map[string]string, len(o.FieldNames)+len(names))
+		for name, newName := range o.FieldNames {
+			fieldNames[name] = newName
+		}
+		for name, newName := range names {
+			fieldNames[name] = newName
+		}
+		o.FieldNames = fieldNames
+	}
+}
+
+// RenameFields will decorate the logger to rename the fields with the configured field names
+func (o *Options) RenameFields(l logger.LoggerInterface) logger.LoggerInterface {
+	if len(o.FieldNames) == 0 || l == nil {
+		return l
+	}
+	return &renamingLogger{LoggerInterface: l, names: o.FieldNames}
+}
+
+type renamingLogger struct {
+	logger.LoggerInterface
+	names map[string]string
+}
+
+func (l *renamingLogger) Log(message string, level logger.Level, fields ...logger.Field) {
+	renamed := make([]logger.Field, len(fields))
+	for i, field := range fields {
+		if name, ok := l.names[field.Name]; ok {
+			field.Name = name
+		}
+		renamed[i] = field
+	}
+	l.LoggerInterface.Log(message, level, renamed...)
+}
+
+func (l *renamingLogger) Enabled(level logger.Level) bool {
+	return levelEnabled(l.LoggerInterface, level)
+}
+
+func (l *renamingLogger) Debug(message string, fields ...logger.Field) {
+	l.Log(message, logger.DebugLevel, fields...)
+}
+
+func (l *renamingLogger) Info(message string, fields ...logger.Field) {
+	l.Log(message, logger.InfoLevel, fields...)
+}
+
+func (l *renamingLogger) Notice(message string, fields ...logger.Field) {
+	l.Log(message, logger.NoticeLevel, fields...)
+}
+
+func (l *renamingLogger) Warning(message string, fields ...logger.Field) {
+	l.Log(message, logger.WarningLevel, fields...)
+}
+
+func (l *renamingLogger) Error(message string, fields ...logger.Field) {
+	l.Log(message, logger.ErrorLevel, fields...)
+}
+
+func (l *renamingLogger) Critical(message string, fields ...logger.Field) {
+	l.Log(message, logger.CriticalLevel, fields...)
+}
+
+func (l *renamingLogger) Alert(message string, fields ...logger.Field) {
+	l.Log(message, logger.AlertLevel, fields...)
+}
+
+func (l *renamingLogger) Emergency(message string, fields ...logger.Field) {
+	l.Log(message, logger.EmergencyLevel, fields...)
+}
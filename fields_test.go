@@ -0,0 +1,36 @@
+package logger_http_test
+
+import (
+	"testing"
+
+	"github.com/gol4ng/logger"
+	testing_logger "github.com/gol4ng/logger/testing"
+	"github.com/stretchr/testify/assert"
+
+	logger_http "github.com/gol4ng/logger-http"
+)
+
+func TestOptions_RenameFields(t *testing.T) {
+	myLogger, store := testing_logger.NewLogger()
+
+	o := logger_http.EvaluateServerOpt()
+	assert.Same(t, myLogger, o.RenameFields(myLogger))
+
+	o = logger_http.EvaluateServerOpt(
+		logger_http.WithFieldNames(logger_http.OTelFieldNames),
+		logger_http.WithFieldNames(map[string]string{"http_kind": "kind"}),
+	)
+	renamed := o.RenameFields(myLogger)
+	renamed.Info("info", logger.String("http_url", "/"), logger.String("http_kind", "server"), logger.String("other", "value"))
+	renamed.Error("error", logger.Any("http_status_code", 500))
+
+	entries := store.GetEntries()
+	assert.Len(t, entries, 2)
+	assert.Equal(t, logger.InfoLevel, entries[0].Level)
+	assert.Equal(t, "/", (*entries[0].Context)["url.full"].Value)
+	assert.Equal(t, "server", (*entries[0].Context)["kind"].Value)
+	assert.Equal(t, "value", (*entries[0].Context)["other"].Value)
+	assert.NotContains(t, *entries[0].Context, "http_url")
+	assert.Equal(t, logger.ErrorLevel, entries[1].Level)
+	assert.EqualValues(t, 500, (*entries[1].Context)["http.response.status_code"].Value)
+}
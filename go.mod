@@ -7,5 +7,5 @@ require (
 	github.com/gol4ng/logger v0.5.10
 	github.com/stretchr/objx v0.4.0 // indirect
 	github.com/stretchr/testify v1.7.1
-	gopkg.in/yaml.v3 v3.0.0-20220512140231-539c8e751b99 // indirect
+	gopkg.in/yaml.v3 v3.0.0-20220512140231-539c8e751b99
 )
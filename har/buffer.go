@@ -0,0 +1,47 @@
+package har
+
+import (
+	"io"
+	"sync"
+)
+
+// Buffer is an in-memory Sink keeping the last entries
+type Buffer struct {
+	mu         sync.Mutex
+	entries    []Entry
+	maxEntries int
+}
+
+// NewBuffer will create a Buffer keeping up to maxEntries entries, 0 means unlimited
+func NewBuffer(maxEntries int) *Buffer {
+	return &Buffer{maxEntries: maxEntries}
+}
+
+func (b *Buffer) Add(entry Entry) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.entries = append(b.entries, entry)
+	if b.maxEntries > 0 && len(b.entries) > b.maxEntries {
+		b.entries = append([]Entry{}, b.entries[len(b.entries)-b.maxEntries:]...)
+	}
+	return nil
+}
+
+// Entries will return a copy of the buffered entries
+func (b *Buffer) Entries() []Entry {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return append([]Entry{}, b.entries...)
+}
+
+// Reset will drop every buffered entry
+func (b *Buffer) Reset() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.entries = nil
+}
+
+// Dump will write the buffered entries as a HAR document
+func (b *Buffer) Dump(w io.Writer) error {
+	return NewDocument(b.Entries()).Write(w)
+}
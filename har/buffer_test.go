@@ -0,0 +1,40 @@
+package har_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/gol4ng/logger-http/har"
+)
+
+func TestBuffer(t *testing.T) {
+	buffer := har.NewBuffer(2)
+
+	assert.Nil(t, buffer.Add(har.Entry{Comment: "first"}))
+	assert.Nil(t, buffer.Add(har.Entry{Comment: "second"}))
+	assert.Nil(t, buffer.Add(har.Entry{Comment: "third"}))
+
+	entries := buffer.Entries()
+	assert.Len(t, entries, 2)
+	assert.Equal(t, "second", entries[0].Comment)
+	assert.Equal(t, "third", entries[1].Comment)
+
+	output := &bytes.Buffer{}
+	assert.Nil(t, buffer.Dump(output))
+
+	document := har.Document{}
+	assert.Nil(t, json.Unmarshal(output.Bytes(), &document))
+	assert.Equal(t, "1.2", document.Log.Version)
+	assert.Equal(t, har.Creator, document.Log.Creator)
+	assert.Equal(t, entries, document.Log.Entries)
+
+	buffer.Reset()
+	assert.Len(t, buffer.Entries(), 0)
+
+	output.Reset()
+	assert.Nil(t, buffer.Dump(output))
+	assert.Contains(t, output.String(), `"entries": []`)
+}
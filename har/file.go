@@ -0,0 +1,141 @@
+package har
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+)
+
+// ErrClosed is returned when adding an entry to a closed RotatingFile
+var ErrClosed = errors.New("har: file is closed")
+
+// RotatingFile is a Sink writing HAR documents of maxEntries entries to a file
+// entries are buffered in memory and every full document is written by a background goroutine,
+// the previous document is rotated to path.1, path.1 to path.2 ... up to maxBackups
+// Close must be called to write the last partial document
+type RotatingFile struct {
+	mu         sync.Mutex
+	path       string
+	maxEntries int
+	maxBackups int
+	entries    []Entry
+	closed     bool
+	documents  chan []Entry
+	done       chan struct{}
+
+	errMu sync.Mutex
+	err   error
+}
+
+// NewRotatingFile will create a RotatingFile and start its writer, it panics when maxEntries is not greater than 0
+func NewRotatingFile(path string, maxEntries int, maxBackups int) *RotatingFile {
+	if maxEntries <= 0 {
+		panic(errors.New("har: maxEntries must be greater than 0"))
+	}
+	f := &RotatingFile{
+		path:       path,
+		maxEntries: maxEntries,
+		maxBackups: maxBackups,
+		entries:    make([]Entry, 0, maxEntries),
+		documents:  make(chan []Entry, 1),
+		done:       make(chan struct{}),
+	}
+	go f.run()
+	return f
+}
+
+// Add will buffer the entry, a full document is handed to the writer without doing any i/o
+// the error of a previous background write is returned once
+func (f *RotatingFile) Add(entry Entry) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.closed {
+		return ErrClosed
+	}
+	f.entries = append(f.entries, entry)
+	if len(f.entries) >= f.maxEntries {
+		// blocks only when the writer is more than one document late
+		f.documents <- f.entries
+		f.entries = make([]Entry, 0, f.maxEntries)
+	}
+	return f.takeErr()
+}
+
+// Close will write the buffered entries and wait for the writer
+func (f *RotatingFile) Close() error {
+	f.mu.Lock()
+	if f.closed {
+		f.mu.Unlock()
+		return nil
+	}
+	f.closed = true
+	if len(f.entries) > 0 {
+		f.documents <- f.entries
+		f.entries = nil
+	}
+	close(f.documents)
+	f.mu.Unlock()
+
+	<-f.done
+	return f.takeErr()
+}
+
+func (f *RotatingFile) run() {
+	defer close(f.done)
+	for entries := range f.documents {
+		err := f.rotate()
+		if err == nil {
+			err = f.write(entries)
+		}
+		if err != nil {
+			f.errMu.Lock()
+			f.err = err
+			f.errMu.Unlock()
+		}
+	}
+}
+
+func (f *RotatingFile) takeErr() error {
+	f.errMu.Lock()
+	defer f.errMu.Unlock()
+	err := f.err
+	f.err = nil
+	return err
+}
+
+func (f *RotatingFile) write(entries []Entry) error {
+	tmpPath := f.path + ".tmp"
+	file, err := os.Create(tmpPath)
+	if err != nil {
+		return err
+	}
+	if err := NewDocument(entries).Write(file); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmpPath, f.path)
+}
+
+func (f *RotatingFile) rotate() error {
+	if f.maxBackups <= 0 {
+		// the next document replaces the current one
+		return nil
+	}
+	for i := f.maxBackups - 1; i > 0; i-- {
+		if err := os.Rename(backupPath(f.path, i), backupPath(f.path, i+1)); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	if err := os.Rename(f.path, backupPath(f.path, 1)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+func backupPath(path string, index int) string {
+	return fmt.Sprintf("%s.%d", path, index)
+}
@@ -0,0 +1,74 @@
+package har_test
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/gol4ng/logger-http/har"
+)
+
+func readDocument(t *testing.T, path string) har.Document {
+	content, err := ioutil.ReadFile(path)
+	assert.Nil(t, err)
+	document := har.Document{}
+	assert.Nil(t, json.Unmarshal(content, &document))
+	return document
+}
+
+func TestRotatingFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "har")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "client.har")
+
+	file := har.NewRotatingFile(path, 2, 1)
+	for _, comment := range []string{"first", "second", "third", "fourth", "fifth"} {
+		assert.Nil(t, file.Add(har.Entry{Comment: comment}))
+	}
+	assert.Nil(t, file.Close())
+	assert.Nil(t, file.Close())
+	assert.Equal(t, har.ErrClosed, file.Add(har.Entry{}))
+
+	entries := readDocument(t, path).Log.Entries
+	assert.Len(t, entries, 1)
+	assert.Equal(t, "fifth", entries[0].Comment)
+	entries = readDocument(t, path+".1").Log.Entries
+	assert.Len(t, entries, 2)
+	assert.Equal(t, "third", entries[0].Comment)
+	assert.Equal(t, "fourth", entries[1].Comment)
+	_, err = os.Stat(path + ".2")
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestRotatingFile_WithoutBackup(t *testing.T) {
+	dir, err := ioutil.TempDir("", "har")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "client.har")
+
+	file := har.NewRotatingFile(path, 1, 0)
+	assert.Nil(t, file.Add(har.Entry{Comment: "first"}))
+	assert.Nil(t, file.Add(har.Entry{Comment: "second"}))
+	assert.Nil(t, file.Close())
+
+	entries := readDocument(t, path).Log.Entries
+	assert.Len(t, entries, 1)
+	assert.Equal(t, "second", entries[0].Comment)
+	_, err = os.Stat(path + ".1")
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestRotatingFile_WriteError(t *testing.T) {
+	file := har.NewRotatingFile(filepath.Join(os.TempDir(), "not-existing-dir", "client.har"), 1, 0)
+	file.Add(har.Entry{})
+	assert.Error(t, file.Close())
+}
+
+func TestNewRotatingFile_InvalidMaxEntries(t *testing.T) {
+	assert.Panics(t, func() { har.NewRotatingFile("client.har", 0, 1) })
+}
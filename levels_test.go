@@ -0,0 +1,77 @@
+package logger_http_test
+
+import (
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gol4ng/logger"
+	"github.com/stretchr/testify/assert"
+
+	logger_http "github.com/gol4ng/logger-http"
+)
+
+type levelEnablerLogger struct {
+	logger.LoggerInterface
+	level logger.Level
+}
+
+func (l *levelEnablerLogger) Enabled(level logger.Level) bool {
+	return level <= l.level
+}
+
+func TestWithMinLevel(t *testing.T) {
+	assert.Equal(t, logger.DebugLevel, logger_http.EvaluateServerOpt().MinLevel)
+
+	o := logger_http.EvaluateServerOpt(logger_http.WithMinLevel(logger.WarningLevel))
+	assert.Equal(t, logger.WarningLevel, o.MinLevel)
+	assert.Equal(t, logger.DebugLevel, o.DebugPolicy().MinLevel)
+}
+
+func TestOptions_Enabled(t *testing.T) {
+	tests := []struct {
+		name     string
+		minLevel logger.Level
+		logger   logger.LoggerInterface
+		level    logger.Level
+		expected bool
+	}{
+		{name: "default", minLevel: logger.DebugLevel, logger: logger.NewNopLogger(), level: logger.DebugLevel, expected: true},
+		{name: "under min level", minLevel: logger.InfoLevel, logger: logger.NewNopLogger(), level: logger.DebugLevel, expected: false},
+		{name: "above min level", minLevel: logger.InfoLevel, logger: logger.NewNopLogger(), level: logger.ErrorLevel, expected: true},
+		{name: "disabled by logger", minLevel: logger.DebugLevel, logger: &levelEnablerLogger{level: logger.WarningLevel}, level: logger.InfoLevel, expected: false},
+		{name: "enabled by logger", minLevel: logger.DebugLevel, logger: &levelEnablerLogger{level: logger.WarningLevel}, level: logger.WarningLevel, expected: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o := logger_http.EvaluateServerOpt(logger_http.WithMinLevel(tt.minLevel))
+			assert.Equal(t, tt.expected, o.Enabled(tt.logger, tt.level))
+		})
+	}
+}
+
+func TestOptions_Enabled_WrappedLogger(t *testing.T) {
+	emitter := logger_http.NewAsyncEmitter(1, 1, logger_http.DropOnFull)
+	o := logger_http.EvaluateServerOpt(
+		logger_http.WithFieldNames(logger_http.OTelFieldNames),
+		logger_http.WithAsyncEmitter(emitter),
+	)
+	wrapped := o.Emitter.Logger(o.RenameFields(&levelEnablerLogger{level: logger.WarningLevel}))
+
+	assert.False(t, o.Enabled(wrapped, logger.InfoLevel))
+	assert.True(t, o.Enabled(wrapped, logger.ErrorLevel))
+}
+
+func TestReleaseContext(t *testing.T) {
+	request := httptest.NewRequest("GET", "http://127.0.0.1/my-fake-url", nil)
+	loggerContext := logger_http.FeedContext(nil, request.Context(), request, time.Now())
+	fields := *loggerContext.Slice()
+	assert.NotEmpty(t, fields)
+
+	logger_http.ReleaseContext(loggerContext)
+	logger_http.ReleaseContext(nil)
+
+	assert.Empty(t, *loggerContext)
+	// entries already built keep their fields
+	assert.Len(t, fields, 3)
+}
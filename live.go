@@ -0,0 +1,204 @@
+package logger_http
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/gol4ng/logger"
+)
+
+// LiveSettings is the runtime configuration of a LiveConfig
+type LiveSettings struct {
+	Routes []LiveRoute  `json:"routes"`
+	Dump   DumpSettings `json:"dump"`
+}
+
+// LiveRoute overrides the options of every request path starting with PathPrefix, the longest prefix wins
+type LiveRoute struct {
+	PathPrefix string `json:"path_prefix"`
+	// Levels overrides the level of the status codes in range, other status codes keep the configured level
+	Levels []LiveLevel `json:"levels,omitempty"`
+	// SampleRate overrides the sampler when set, between 0 and 1
+	SampleRate *float64 `json:"sample_rate,omitempty"`
+	// Disabled filters every request of the route
+	Disabled bool `json:"disabled,omitempty"`
+}
+
+// LiveLevel is the level of an inclusive range of status codes
+type LiveLevel struct {
+	From  int    `json:"from" yaml:"from"`
+	To    int    `json:"to" yaml:"to"`
+	Level string `json:"level" yaml:"level"`
+}
+
+// DumpSettings toggles the wire dumps, see DumpSwitch
+type DumpSettings struct {
+	All    bool     `json:"all,omitempty"`
+	Hosts  []string `json:"hosts,omitempty"`
+	Routes []string `json:"routes,omitempty"`
+}
+
+// LiveConfig is a configuration swapped atomically at runtime and consulted on every request
+// see WithLiveConfig and AdminHandler
+type LiveConfig struct {
+	state atomic.Value
+	// mutex serializes the updates, reads stay lock free
+	mutex sync.Mutex
+}
+
+type liveState struct {
+	settings LiveSettings
+	routes   []liveRoute
+	dump     *DumpSwitch
+}
+
+type liveRoute struct {
+	pathPrefix string
+	levels     []liveLevel
+	sampler    Sampler
+	disabled   bool
+}
+
+type liveLevel struct {
+	statusRange StatusRange
+	level       logger.Level
+}
+
+// NewLiveConfig will create a LiveConfig with the given settings
+func NewLiveConfig(settings LiveSettings) (*LiveConfig, error) {
+	c := &LiveConfig{}
+	if err := c.Store(settings); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// WithLiveConfig will apply the live configuration overrides on every request
+func WithLiveConfig(live *LiveConfig) Option {
+	return func(o *Options) {
+		o.Live = live
+	}
+}
+
+// Load will return the current settings
+func (c *LiveConfig) Load() LiveSettings {
+	return c.load().settings
+}
+
+// Store will validate the settings and swap them atomically
+func (c *LiveConfig) Store(settings LiveSettings) error {
+	_, err := c.Swap(settings)
+	return err
+}
+
+// Swap will validate the settings and swap them atomically, returning the replaced settings
+// concurrent calls are serialized so every caller gets the settings it actually replaced
+func (c *LiveConfig) Swap(settings LiveSettings) (LiveSettings, error) {
+	state := &liveState{settings: settings, dump: NewDumpSwitch()}
+	for i, route := range settings.Routes {
+		compiled, err := compileLiveRoute(route)
+		if err != nil {
+			return LiveSettings{}, fmt.Errorf("routes[%d]: %w", i, err)
+		}
+		state.routes = append(state.routes, compiled)
+	}
+	sort.SliceStable(state.routes, func(i, j int) bool {
+		return len(state.routes[i].pathPrefix) > len(state.routes[j].pathPrefix)
+	})
+	state.dump.EnableAll(settings.Dump.All)
+	for _, host := range settings.Dump.Hosts {
+		state.dump.EnableHost(host, true)
+	}
+	for _, pathPrefix := range settings.Dump.Routes {
+		state.dump.EnableRoute(pathPrefix, true)
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	previous := c.load().settings
+	c.state.Store(state)
+	return previous, nil
+}
+
+func (c *LiveConfig) load() *liveState {
+	state, _ := c.state.Load().(*liveState)
+	if state == nil {
+		return &liveState{}
+	}
+	return state
+}
+
+func (c *LiveConfig) apply(policy *Options, request *http.Request) *Options {
+	for _, route := range c.load().routes {
+		if !MatchPathPrefix(request.URL.Path, route.pathPrefix) {
+			continue
+		}
+		livePolicy := *policy
+		if route.disabled {
+			livePolicy.RequestFilter = func(*http.Request) bool { return true }
+		}
+		if route.sampler != nil {
+			livePolicy.Sampler = route.sampler
+		}
+		if len(route.levels) > 0 {
+			livePolicy.LevelFunc = rangeLevels(route.levels, policy.LevelFunc)
+		}
+		return &livePolicy
+	}
+	return policy
+}
+
+func compileLiveRoute(route LiveRoute) (liveRoute, error) {
+	compiled := liveRoute{pathPrefix: route.PathPrefix, disabled: route.Disabled}
+	if !strings.HasPrefix(route.PathPrefix, "/") {
+		return compiled, fmt.Errorf("path_prefix: %q must start with /", route.PathPrefix)
+	}
+	if route.SampleRate != nil {
+		if err := validateSampleRate(*route.SampleRate); err != nil {
+			return compiled, fmt.Errorf("sample_rate: %w", err)
+		}
+		compiled.sampler = RateSampler(*route.SampleRate)
+	}
+	levels, err := compileLiveLevels(route.Levels)
+	compiled.levels = levels
+	return compiled, err
+}
+
+func compileLiveLevels(levels []LiveLevel) ([]liveLevel, error) {
+	compiled := make([]liveLevel, 0, len(levels))
+	for i, level := range levels {
+		if level.From > level.To {
+			return nil, fmt.Errorf("levels[%d]: from %d is greater than to %d", i, level.From, level.To)
+		}
+		parsed, err := ParseLevel(level.Level)
+		if err != nil {
+			return nil, fmt.Errorf("levels[%d].level: %w", i, err)
+		}
+		compiled = append(compiled, liveLevel{statusRange: StatusRange{From: level.From, To: level.To}, level: parsed})
+	}
+	return compiled, nil
+}
+
+// rangeLevels returns the level of the first range containing the status code, the fallback level otherwise
+func rangeLevels(levels []liveLevel, fallback CodeToLevel) CodeToLevel {
+	return func(statusCode int) logger.Level {
+		for _, level := range levels {
+			if level.statusRange.Contains(statusCode) {
+				return level.level
+			}
+		}
+		return fallback(statusCode)
+	}
+}
+
+// ParseLevel will convert a level name (debug, info, notice, warning, error, critical, alert, emergency) into a logger.Level
+func ParseLevel(name string) (logger.Level, error) {
+	level := logger.LevelString(name).Level()
+	if level.String() != strings.ToLower(name) {
+		return level, fmt.Errorf("unknown level %q", name)
+	}
+	return level, nil
+}
@@ -0,0 +1,87 @@
+package logger_http_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gol4ng/logger"
+	"github.com/stretchr/testify/assert"
+
+	logger_http "github.com/gol4ng/logger-http"
+)
+
+func TestParseLevel(t *testing.T) {
+	level, err := logger_http.ParseLevel("Warning")
+	assert.Nil(t, err)
+	assert.Equal(t, logger.WarningLevel, level)
+
+	_, err = logger_http.ParseLevel("verbose")
+	assert.EqualError(t, err, `unknown level "verbose"`)
+}
+
+func TestLiveConfig(t *testing.T) {
+	live, err := logger_http.NewLiveConfig(logger_http.LiveSettings{})
+	assert.Nil(t, err)
+	o := logger_http.EvaluateServerOpt(logger_http.WithLiveConfig(live))
+
+	apiRequest := httptest.NewRequest(http.MethodGet, "http://127.0.0.1/api/users", nil)
+	healthRequest := httptest.NewRequest(http.MethodGet, "http://127.0.0.1/health", nil)
+	assert.Same(t, o, o.Policy(apiRequest))
+	assert.False(t, o.DumpEnabled(apiRequest))
+
+	rate := 0.0
+	assert.Nil(t, live.Store(logger_http.LiveSettings{
+		Routes: []logger_http.LiveRoute{
+			{PathPrefix: "/api", Levels: []logger_http.LiveLevel{{From: 200, To: 299, Level: "debug"}}, SampleRate: &rate},
+			{PathPrefix: "/health", Disabled: true},
+		},
+		Dump: logger_http.DumpSettings{Routes: []string{"/api"}},
+	}))
+
+	apiPolicy := o.Policy(apiRequest)
+	assert.Equal(t, logger.DebugLevel, apiPolicy.LevelFunc(http.StatusOK))
+	assert.Equal(t, logger.ErrorLevel, apiPolicy.LevelFunc(http.StatusInternalServerError))
+	assert.False(t, apiPolicy.Sampler(apiRequest))
+	assert.Nil(t, apiPolicy.RequestFilter)
+	assert.True(t, apiPolicy.DumpEnabled(apiRequest))
+
+	healthPolicy := o.Policy(healthRequest)
+	assert.True(t, healthPolicy.RequestFilter(healthRequest))
+	assert.False(t, healthPolicy.DumpEnabled(healthRequest))
+
+	assert.Equal(t, logger.InfoLevel, o.LevelFunc(http.StatusOK))
+}
+
+func TestLiveConfig_Store_Invalid(t *testing.T) {
+	rate := 1.5
+	tests := []struct {
+		settings logger_http.LiveSettings
+		expected string
+	}{
+		{
+			settings: logger_http.LiveSettings{Routes: []logger_http.LiveRoute{{PathPrefix: "api"}}},
+			expected: `routes[0]: path_prefix: "api" must start with /`,
+		},
+		{
+			settings: logger_http.LiveSettings{Routes: []logger_http.LiveRoute{{PathPrefix: "/"}, {PathPrefix: "/api", SampleRate: &rate}}},
+			expected: `routes[1]: sample_rate: 1.5 must be between 0 and 1`,
+		},
+		{
+			settings: logger_http.LiveSettings{Routes: []logger_http.LiveRoute{{PathPrefix: "/", Levels: []logger_http.LiveLevel{{From: 500, To: 400, Level: "info"}}}}},
+			expected: `routes[0]: levels[0]: from 500 is greater than to 400`,
+		},
+		{
+			settings: logger_http.LiveSettings{Routes: []logger_http.LiveRoute{{PathPrefix: "/", Levels: []logger_http.LiveLevel{{From: 200, To: 299, Level: "verbose"}}}}},
+			expected: `routes[0]: levels[0].level: unknown level "verbose"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expected, func(t *testing.T) {
+			live, err := logger_http.NewLiveConfig(logger_http.LiveSettings{})
+			assert.Nil(t, err)
+			assert.EqualError(t, live.Store(tt.settings), tt.expected)
+			assert.Equal(t, logger_http.LiveSettings{}, live.Load())
+		})
+	}
+}
@@ -0,0 +1,139 @@
+package loggerhttptest
+
+import (
+	"strings"
+
+	"github.com/gol4ng/logger"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestingT is the subset of *testing.T used by the assertions
+type TestingT interface {
+	Errorf(format string, args ...interface{})
+}
+
+// EntriesAssertion provides fluent assertions on a list of entries
+type EntriesAssertion struct {
+	t       TestingT
+	entries []logger.Entry
+}
+
+// AssertEntries will start a fluent assertion on the given entries
+func AssertEntries(t TestingT, entries []logger.Entry) *EntriesAssertion {
+	return &EntriesAssertion{t: t, entries: entries}
+}
+
+// Len asserts the number of entries
+func (a *EntriesAssertion) Len(length int) *EntriesAssertion {
+	assert.Len(a.t, a.entries, length)
+	return a
+}
+
+// MessagesInOrder asserts that entries containing each given message part appear in that order
+// other entries may be interleaved
+func (a *EntriesAssertion) MessagesInOrder(messageParts ...string) *EntriesAssertion {
+	index := 0
+	for _, entry := range a.entries {
+		if index < len(messageParts) && strings.Contains(entry.Message, messageParts[index]) {
+			index++
+		}
+	}
+	if index < len(messageParts) {
+		assert.Fail(a.t, "entries are not in the expected order", "entry containing %q not found after %v", messageParts[index], messageParts[:index])
+	}
+	return a
+}
+
+// Entry will start a fluent assertion on the entry at the given index
+// a negative index counts from the end
+func (a *EntriesAssertion) Entry(index int) *EntryAssertion {
+	if index < 0 {
+		index += len(a.entries)
+	}
+	if index < 0 || index >= len(a.entries) {
+		assert.Fail(a.t, "entry not found", "no entry at index %d, %d entries recorded", index, len(a.entries))
+		return &EntryAssertion{t: a.t, missing: true}
+	}
+	return &EntryAssertion{t: a.t, entry: a.entries[index]}
+}
+
+// Each will start a fluent assertion on every entry
+func (a *EntriesAssertion) Each(f func(*EntryAssertion)) *EntriesAssertion {
+	for _, entry := range a.entries {
+		f(&EntryAssertion{t: a.t, entry: entry})
+	}
+	return a
+}
+
+// EntryAssertion provides fluent assertions on a single entry
+type EntryAssertion struct {
+	t       TestingT
+	entry   logger.Entry
+	missing bool
+}
+
+// Level asserts the entry level
+func (a *EntryAssertion) Level(level logger.Level) *EntryAssertion {
+	if !a.missing {
+		assert.Equal(a.t, level, a.entry.Level, "entry %q level", a.entry.Message)
+	}
+	return a
+}
+
+// Message asserts the entry message
+func (a *EntryAssertion) Message(message string) *EntryAssertion {
+	if !a.missing {
+		assert.Equal(a.t, message, a.entry.Message)
+	}
+	return a
+}
+
+// MessageContains asserts the entry message contains the given part
+func (a *EntryAssertion) MessageContains(messagePart string) *EntryAssertion {
+	if !a.missing {
+		assert.Contains(a.t, a.entry.Message, messagePart)
+	}
+	return a
+}
+
+// HasField asserts the entry context contains the field
+func (a *EntryAssertion) HasField(name string) *EntryAssertion {
+	if !a.missing && !a.hasField(name) {
+		assert.Fail(a.t, "field not found", "entry %q has no field %q", a.entry.Message, name)
+	}
+	return a
+}
+
+// NotHasField asserts the entry context does not contain the field
+func (a *EntryAssertion) NotHasField(name string) *EntryAssertion {
+	if !a.missing && a.hasField(name) {
+		assert.Fail(a.t, "unexpected field", "entry %q has field %q", a.entry.Message, name)
+	}
+	return a
+}
+
+// Field asserts the entry context field value
+func (a *EntryAssertion) Field(name string, value interface{}) *EntryAssertion {
+	if a.missing {
+		return a
+	}
+	if !a.hasField(name) {
+		assert.Fail(a.t, "field not found", "entry %q has no field %q", a.entry.Message, name)
+		return a
+	}
+	assert.Equal(a.t, value, (*a.entry.Context)[name].Value, "entry %q field %q", a.entry.Message, name)
+	return a
+}
+
+// Get will return the entry, useful for custom assertions
+func (a *EntryAssertion) Get() logger.Entry {
+	return a.entry
+}
+
+func (a *EntryAssertion) hasField(name string) bool {
+	if a.entry.Context == nil {
+		return false
+	}
+	_, ok := (*a.entry.Context)[name]
+	return ok
+}
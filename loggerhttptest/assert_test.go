@@ -0,0 +1,61 @@
+package loggerhttptest_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/gol4ng/logger"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/gol4ng/logger-http/loggerhttptest"
+)
+
+type fakeT struct {
+	errors []string
+}
+
+func (f *fakeT) Errorf(format string, args ...interface{}) {
+	f.errors = append(f.errors, fmt.Sprintf(format, args...))
+}
+
+func TestAssertEntries(t *testing.T) {
+	entries := []logger.Entry{
+		{Message: "first message", Level: logger.DebugLevel, Context: logger.NewContext().Add("key", "value")},
+		{Message: "second message", Level: logger.InfoLevel, Context: logger.NewContext()},
+	}
+
+	ft := &fakeT{}
+	loggerhttptest.AssertEntries(ft, entries).
+		Len(2).
+		MessagesInOrder("first", "second").
+		Entry(0).
+		Level(logger.DebugLevel).
+		Message("first message").
+		Field("key", "value").
+		HasField("key").
+		NotHasField("other")
+	assert.Empty(t, ft.errors)
+
+	tests := []struct {
+		name      string
+		assertion func(a *loggerhttptest.EntriesAssertion)
+	}{
+		{name: "len", assertion: func(a *loggerhttptest.EntriesAssertion) { a.Len(3) }},
+		{name: "order", assertion: func(a *loggerhttptest.EntriesAssertion) { a.MessagesInOrder("second", "first") }},
+		{name: "missing entry", assertion: func(a *loggerhttptest.EntriesAssertion) { a.Entry(2).Level(logger.InfoLevel) }},
+		{name: "level", assertion: func(a *loggerhttptest.EntriesAssertion) { a.Entry(0).Level(logger.InfoLevel) }},
+		{name: "message", assertion: func(a *loggerhttptest.EntriesAssertion) { a.Entry(0).Message("second message") }},
+		{name: "message contains", assertion: func(a *loggerhttptest.EntriesAssertion) { a.Entry(0).MessageContains("second") }},
+		{name: "field value", assertion: func(a *loggerhttptest.EntriesAssertion) { a.Entry(0).Field("key", "other") }},
+		{name: "missing field", assertion: func(a *loggerhttptest.EntriesAssertion) { a.Entry(1).Field("key", "value") }},
+		{name: "has field", assertion: func(a *loggerhttptest.EntriesAssertion) { a.Entry(1).HasField("key") }},
+		{name: "not has field", assertion: func(a *loggerhttptest.EntriesAssertion) { a.Entry(0).NotHasField("key") }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ft := &fakeT{}
+			tt.assertion(loggerhttptest.AssertEntries(ft, entries))
+			assert.Len(t, ft.errors, 1)
+		})
+	}
+}
@@ -0,0 +1,85 @@
+// Package loggerhttptest provides utilities for testing code using logger-http
+package loggerhttptest
+
+import (
+	"sync"
+	"time"
+)
+
+// Clock is a controllable logger_http.Clock and logger_http.TickerClock
+// time only moves when Add or Set is called, the tickers tick when the time moves past their next tick
+type Clock struct {
+	mu      sync.Mutex
+	now     time.Time
+	tickers map[*ticker]struct{}
+}
+
+type ticker struct {
+	ticks    chan time.Time
+	period   time.Duration
+	nextTick time.Time
+}
+
+// NewClock will create a Clock starting at the given time
+func NewClock(now time.Time) *Clock {
+	return &Clock{now: now}
+}
+
+func (c *Clock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *Clock) Since(t time.Time) time.Duration {
+	return c.Now().Sub(t)
+}
+
+// Add will move the clock forward by the given duration
+func (c *Clock) Add(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+	c.tick()
+}
+
+// Set will move the clock to the given time
+func (c *Clock) Set(now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = now
+	c.tick()
+}
+
+// NewTicker will create a ticker ticking every d of the clock time
+func (c *Clock) NewTicker(d time.Duration) (<-chan time.Time, func()) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.tickers == nil {
+		c.tickers = map[*ticker]struct{}{}
+	}
+	t := &ticker{ticks: make(chan time.Time, 1), period: d, nextTick: c.now.Add(d)}
+	c.tickers[t] = struct{}{}
+	return t.ticks, func() {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		delete(c.tickers, t)
+	}
+}
+
+// tick must be called with the lock held
+func (c *Clock) tick() {
+	for t := range c.tickers {
+		if c.now.Before(t.nextTick) {
+			continue
+		}
+		// like time.Ticker, the ticks missed by a slow receiver are dropped
+		select {
+		case t.ticks <- c.now:
+		default:
+		}
+		for !c.now.Before(t.nextTick) {
+			t.nextTick = t.nextTick.Add(t.period)
+		}
+	}
+}
@@ -0,0 +1,26 @@
+package loggerhttptest_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	logger_http "github.com/gol4ng/logger-http"
+	"github.com/gol4ng/logger-http/loggerhttptest"
+)
+
+func TestClock(t *testing.T) {
+	start := time.Date(2019, 12, 13, 17, 1, 13, 0, time.UTC)
+
+	var clock logger_http.Clock = loggerhttptest.NewClock(start)
+	assert.Equal(t, start, clock.Now())
+	assert.Equal(t, time.Duration(0), clock.Since(start))
+
+	clock.(*loggerhttptest.Clock).Add(2 * time.Second)
+	assert.Equal(t, start.Add(2*time.Second), clock.Now())
+	assert.Equal(t, 2*time.Second, clock.Since(start))
+
+	clock.(*loggerhttptest.Clock).Set(start)
+	assert.Equal(t, start, clock.Now())
+}
@@ -0,0 +1,55 @@
+package loggerhttptest
+
+import (
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/gol4ng/logger"
+	"github.com/gol4ng/logger/handler"
+
+	logger_http "github.com/gol4ng/logger-http"
+	"github.com/gol4ng/logger-http/middleware"
+	"github.com/gol4ng/logger-http/tripperware"
+)
+
+// Recorder is an in-memory logger recording every entry
+type Recorder struct {
+	*logger.Logger
+	store *handler.Memory
+}
+
+// NewRecorder will create an empty Recorder
+func NewRecorder() *Recorder {
+	store := handler.NewMemory()
+	return &Recorder{
+		Logger: logger.NewLogger(store.Handle),
+		store:  store,
+	}
+}
+
+// Entries will return the recorded entries in order
+func (r *Recorder) Entries() []logger.Entry {
+	return r.store.GetEntries()
+}
+
+// Reset will forget every recorded entry
+func (r *Recorder) Reset() {
+	r.store.CleanEntries()
+}
+
+// Assert will start a fluent assertion on the recorded entries
+func (r *Recorder) Assert(t TestingT) *EntriesAssertion {
+	return AssertEntries(t, r.Entries())
+}
+
+// Serve will run the request through the handler decorated with middleware.Logger
+func (r *Recorder) Serve(h http.Handler, req *http.Request, opts ...logger_http.Option) *httptest.ResponseRecorder {
+	responseRecorder := httptest.NewRecorder()
+	middleware.Logger(r, opts...)(h).ServeHTTP(responseRecorder, req)
+	return responseRecorder
+}
+
+// RoundTrip will send the request through the transport decorated with tripperware.Logger
+func (r *Recorder) RoundTrip(transport http.RoundTripper, req *http.Request, opts ...logger_http.Option) (*http.Response, error) {
+	return tripperware.Logger(r, opts...)(transport).RoundTrip(req)
+}
@@ -0,0 +1,56 @@
+package loggerhttptest_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gol4ng/logger"
+	"github.com/stretchr/testify/assert"
+
+	logger_http "github.com/gol4ng/logger-http"
+	"github.com/gol4ng/logger-http/loggerhttptest"
+)
+
+func TestRecorder_Serve(t *testing.T) {
+	recorder := loggerhttptest.NewRecorder()
+
+	h := http.HandlerFunc(func(writer http.ResponseWriter, innerRequest *http.Request) {
+		logger.FromContext(innerRequest.Context(), recorder).Info("handler info log")
+		writer.WriteHeader(http.StatusNotFound)
+	})
+
+	responseRecorder := recorder.Serve(h, httptest.NewRequest(http.MethodGet, "http://127.0.0.1/my-fake-url", nil), logger_http.WithBodyCapture(10))
+	assert.Equal(t, http.StatusNotFound, responseRecorder.Code)
+
+	recorder.Assert(t).
+		Len(3).
+		MessagesInOrder("http server received", "handler info log", "http server GET").
+		Each(func(entry *loggerhttptest.EntryAssertion) {
+			entry.NotHasField("http_panic")
+		})
+	recorder.Assert(t).Entry(-1).
+		Level(logger.WarningLevel).
+		MessageContains("[status_code:404").
+		Field("http_kind", "server").
+		Field("http_status_code", int64(404)).
+		HasField("http_response_body")
+
+	recorder.Reset()
+	recorder.Assert(t).Len(0)
+}
+
+func TestRecorder_RoundTrip(t *testing.T) {
+	recorder := loggerhttptest.NewRecorder()
+
+	_, err := recorder.RoundTrip(loggerhttptest.ErrorTransport(errors.New("my transport error")), httptest.NewRequest(http.MethodGet, "http://127.0.0.1/my-fake-url", nil))
+	assert.EqualError(t, err, "my transport error")
+
+	recorder.Assert(t).
+		Len(2).
+		Entry(1).
+		Level(logger.ErrorLevel).
+		MessageContains("http client error GET http://127.0.0.1/my-fake-url [duration:").
+		Field("http_error_message", "my transport error")
+}
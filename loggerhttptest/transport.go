@@ -0,0 +1,37 @@
+package loggerhttptest
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	"github.com/gol4ng/httpware/v4"
+)
+
+// ResponseTransport will create a http.RoundTripper answering every request with the canned response
+func ResponseTransport(statusCode int, body string, header http.Header) http.RoundTripper {
+	if header == nil {
+		header = http.Header{}
+	}
+	return httpware.RoundTripFunc(func(req *http.Request) (*http.Response, error) {
+		return &http.Response{
+			Status:        fmt.Sprintf("%d %s", statusCode, http.StatusText(statusCode)),
+			StatusCode:    statusCode,
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        header.Clone(),
+			Body:          ioutil.NopCloser(strings.NewReader(body)),
+			ContentLength: int64(len(body)),
+			Request:       req,
+		}, nil
+	})
+}
+
+// ErrorTransport will create a http.RoundTripper failing every request with the given error
+func ErrorTransport(err error) http.RoundTripper {
+	return httpware.RoundTripFunc(func(req *http.Request) (*http.Response, error) {
+		return nil, err
+	})
+}
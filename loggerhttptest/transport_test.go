@@ -0,0 +1,36 @@
+package loggerhttptest_test
+
+import (
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/gol4ng/logger-http/loggerhttptest"
+)
+
+func TestResponseTransport(t *testing.T) {
+	transport := loggerhttptest.ResponseTransport(http.StatusTeapot, "my body", http.Header{"X-Fake": {"value"}})
+	request := httptest.NewRequest(http.MethodGet, "http://127.0.0.1/my-fake-url", nil)
+
+	for i := 0; i < 2; i++ {
+		response, err := transport.RoundTrip(request)
+		assert.Nil(t, err)
+		assert.Equal(t, "418 I'm a teapot", response.Status)
+		assert.Equal(t, http.StatusTeapot, response.StatusCode)
+		assert.Equal(t, "value", response.Header.Get("X-Fake"))
+		assert.Equal(t, int64(7), response.ContentLength)
+		assert.Equal(t, request, response.Request)
+		body, _ := ioutil.ReadAll(response.Body)
+		assert.Equal(t, "my body", string(body))
+	}
+}
+
+func TestErrorTransport(t *testing.T) {
+	response, err := loggerhttptest.ErrorTransport(errors.New("my transport error")).RoundTrip(httptest.NewRequest(http.MethodGet, "http://127.0.0.1/my-fake-url", nil))
+	assert.Nil(t, response)
+	assert.EqualError(t, err, "my transport error")
+}
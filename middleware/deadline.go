@@ -0,0 +1,35 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gol4ng/httpware/v4"
+
+	"github.com/gol4ng/logger-http"
+)
+
+// Deadline will turn the budget header set by tripperware.Deadline into a request context deadline
+// an invalid budget is ignored, an exhausted budget (0) gives an already expired deadline,
+// a budget above WithMaxBudget is clamped and an earlier existing deadline is kept
+// eg:
+//
+//	stack := httpware.MiddlewareStack(
+//		middleware.Deadline(), // << before Logger so the budget is logged
+//		middleware.Logger(l),
+//	)
+func Deadline(opts ...logger_http.Option) httpware.Middleware {
+	o := logger_http.EvaluateServerOpt(opts...)
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(writer http.ResponseWriter, req *http.Request) {
+			budget, err := logger_http.ParseBudget(req.Header.Get(o.BudgetHeader), o.MaxBudget)
+			if err != nil {
+				next.ServeHTTP(writer, req)
+				return
+			}
+			ctx, cancel := context.WithDeadline(req.Context(), o.Clock.Now().Add(budget))
+			defer cancel()
+			next.ServeHTTP(writer, req.WithContext(ctx))
+		})
+	}
+}
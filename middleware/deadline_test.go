@@ -0,0 +1,56 @@
+package middleware_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/gol4ng/logger-http"
+	"github.com/gol4ng/logger-http/loggerhttptest"
+	"github.com/gol4ng/logger-http/middleware"
+)
+
+func TestDeadline(t *testing.T) {
+	clock := loggerhttptest.NewClock(time.Now())
+	var deadline time.Time
+	var hasDeadline bool
+	h := http.HandlerFunc(func(writer http.ResponseWriter, innerRequest *http.Request) {
+		deadline, hasDeadline = innerRequest.Context().Deadline()
+	})
+	handler := middleware.Deadline(logger_http.WithClock(clock), logger_http.WithBudgetHeader("X-Budget"), logger_http.WithMaxBudget(time.Minute))(h)
+
+	request := httptest.NewRequest(http.MethodGet, "http://127.0.0.1/my-fake-url", nil)
+	handler.ServeHTTP(httptest.NewRecorder(), request)
+	assert.False(t, hasDeadline)
+
+	for _, invalid := range []string{"invalid", "-1500"} {
+		request.Header.Set("X-Budget", invalid)
+		handler.ServeHTTP(httptest.NewRecorder(), request)
+		assert.False(t, hasDeadline, invalid)
+	}
+
+	request.Header.Set("X-Budget", "0")
+	handler.ServeHTTP(httptest.NewRecorder(), request)
+	assert.True(t, hasDeadline)
+	assert.Equal(t, clock.Now(), deadline)
+
+	request.Header.Set("X-Budget", "9223372036854775807")
+	handler.ServeHTTP(httptest.NewRecorder(), request)
+	assert.True(t, hasDeadline)
+	assert.Equal(t, clock.Now().Add(time.Minute), deadline)
+
+	request.Header.Set("X-Budget", "1500")
+	handler.ServeHTTP(httptest.NewRecorder(), request)
+	assert.True(t, hasDeadline)
+	assert.Equal(t, clock.Now().Add(1500*time.Millisecond), deadline)
+
+	earlier := clock.Now().Add(time.Second)
+	ctx, cancel := context.WithDeadline(request.Context(), earlier)
+	defer cancel()
+	handler.ServeHTTP(httptest.NewRecorder(), request.WithContext(ctx))
+	assert.Equal(t, earlier, deadline)
+}
@@ -1,55 +1,264 @@
 package middleware
 
 import (
-	"fmt"
+	"context"
+	"io"
 	"net/http"
-	"time"
 
 	"github.com/gol4ng/httpware/v4"
 	http_middleware "github.com/gol4ng/httpware/v4/middleware"
 	"github.com/gol4ng/logger"
+	"github.com/gol4ng/logger/middleware"
 
 	"github.com/gol4ng/logger-http"
 )
 
 // Logger will decorate the http.Handler to add support of gol4ng/logger
 func Logger(log logger.LoggerInterface, opts ...logger_http.Option) httpware.Middleware {
+	return newLogger(func(ctx context.Context) logger.LoggerInterface {
+		return logger.FromContext(ctx, log)
+	}, opts...)
+}
+
+func newLogger(loggerFromContext func(context.Context) logger.LoggerInterface, opts ...logger_http.Option) httpware.Middleware {
 	o := logger_http.EvaluateServerOpt(opts...)
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(writer http.ResponseWriter, req *http.Request) {
-			startTime := time.Now()
+			policy := o.Policy(req)
+			if policy.RequestFilter != nil && policy.RequestFilter(req) {
+				next.ServeHTTP(writer, req)
+				return
+			}
+			debugToken := policy.DebugToken(req)
+			if debugToken != "" {
+				policy = policy.DebugPolicy()
+			}
+			sampled := policy.Sampler == nil || policy.Sampler(req)
+
+			startTime := policy.Clock.Now()
 			ctx := req.Context()
+			exchange := policy.NewExchange("server", req, startTime)
+
+			contextLogger := loggerFromContext(ctx)
+			currentLogger := policy.Emitter.Logger(policy.RenameFields(contextLogger))
+			queueDuration, hasQueueDuration := policy.QueueDuration(req, startTime)
+
+			if debugToken != "" {
+				ctx = logger_http.InjectDebugTokenInContext(ctx, debugToken)
+				if wrappableLogger, ok := contextLogger.(logger.WrappableLoggerInterface); ok {
+					ctx = logger.InjectInContext(ctx, wrappableLogger.WrapNew(middleware.Context(
+						logger.NewContext().Add(logger_http.DebugFieldName, true),
+					)))
+				}
+				req = req.WithContext(ctx)
+			}
+
+			var tailLogger *logger_http.TailLogger
+			if policy.TailBufferLimit > 0 {
+				tailLogger = logger_http.NewTailLogger(contextLogger, policy.Clock, policy.TailBufferLimit)
+				ctx = logger.InjectInContext(ctx, tailLogger)
+				req = req.WithContext(ctx)
+			}
+
+			var requestBody []byte
+			captureRequestBody := policy.BodyCaptureLimit > 0 && req.Body != nil
+			if captureRequestBody {
+				requestBody, req.Body = logger_http.PeekBody(req.Body, policy.BodyCaptureLimit)
+			}
+
+			dump := policy.DumpEnabled(req) && policy.Enabled(currentLogger, policy.DumpLevel)
+			var requestDumpBody []byte
+			if dump && policy.DumpBodyLimit > 0 && req.Body != nil {
+				requestDumpBody, req.Body = logger_http.PeekBody(req.Body, policy.DumpBodyLimit)
+			}
 
-			currentLogger := logger.FromContext(ctx, log)
-			currentLoggerContext := logger_http.FeedContext(o.LoggerContextProvider(req), ctx, req, startTime).Add("http_kind", "server")
+			// the logger context is only built once an entry is going to be logged
+			var currentLoggerContext *logger.Context
+			loggerContext := func() *logger.Context {
+				if currentLoggerContext != nil {
+					return currentLoggerContext
+				}
+				currentLoggerContext = logger_http.FeedContext(policy.LoggerContextProvider(req), ctx, req, startTime).
+					Add("http_method", exchange.Method).
+					Add("http_url", exchange.URL).
+					Add("http_kind", "server")
+				if hasQueueDuration {
+					currentLoggerContext.Add("http_queue_duration", queueDuration.Seconds())
+				}
+				if debugToken != "" {
+					currentLoggerContext.Add(logger_http.DebugFieldName, true)
+				}
+				if captureRequestBody {
+					currentLoggerContext.Add("http_request_body", policy.Sanitize(string(requestBody)))
+				}
+				return currentLoggerContext
+			}
+			defer func() {
+				logger_http.ReleaseContext(currentLoggerContext)
+			}()
 
 			writerInterceptor := http_middleware.NewResponseWriterInterceptor(writer)
 			defer func() {
-				duration := time.Since(startTime)
-				currentLoggerContext.Add("http_duration", duration.Seconds())
+				duration := policy.Clock.Since(startTime)
+				exchange.Duration = duration
+				endTime := policy.Clock.Now()
+				endLoggerContext := func() *logger.Context {
+					endContext := loggerContext().Add("http_duration", duration.Seconds())
+					logger_http.FeedBudgetContext(endContext, ctx, endTime)
+					return endContext
+				}
 
 				if err := recover(); err != nil {
-					currentLoggerContext.Add("http_panic", err)
-					currentLogger.Critical(fmt.Sprintf("http server panic %s %s [duration:%s]", req.Method, req.URL, duration), *currentLoggerContext.Slice()...)
+					exchange.Panic = err
+					policy.Observers.OnPanic(exchange)
+					if tailLogger != nil {
+						tailLogger.Flush()
+					}
+					currentLogger.Critical("http server panic "+exchange.Method+" "+exchange.URL+" [duration:"+duration.String()+"]", *endLoggerContext().Add("http_panic", err).Slice()...)
 					panic(err)
 				}
 
-				currentLoggerContext.Add("http_status", http.StatusText(writerInterceptor.StatusCode)).
+				exchange.StatusCode = writerInterceptor.StatusCode
+				exchange.Status = http.StatusText(writerInterceptor.StatusCode)
+				exchange.ResponseLength = int64(len(writerInterceptor.Body))
+				policy.Observers.OnEnd(exchange)
+				if tailLogger != nil {
+					if policy.ShouldFlushTail(writerInterceptor.StatusCode, duration) {
+						tailLogger.Flush()
+					} else {
+						tailLogger.Discard()
+					}
+				}
+
+				if dump {
+					body := writerInterceptor.Body
+					if len(body) > policy.DumpBodyLimit {
+						body = body[:policy.DumpBodyLimit]
+					}
+					responseDump := policy.DumpResponse(&http.Response{
+						Proto:         req.Proto,
+						StatusCode:    writerInterceptor.StatusCode,
+						Header:        writer.Header(),
+						ContentLength: int64(len(writerInterceptor.Body)),
+					}, body)
+					currentLogger.Log("http server response dump "+exchange.Method+" "+exchange.URL, policy.DumpLevel, append(*endLoggerContext().Slice(), logger.String("http_dump", responseDump))...)
+				}
+
+				level := policy.LevelFunc(writerInterceptor.StatusCode)
+				if !sampled && level > logger.WarningLevel || !policy.Enabled(currentLogger, level) {
+					return
+				}
+
+				finalContext := endLoggerContext().
+					Add("http_status", exchange.Status).
 					Add("http_status_code", writerInterceptor.StatusCode).
 					Add("http_response_length", len(writerInterceptor.Body))
 
-				currentLogger.Log(
-					fmt.Sprintf(
-						"http server %s %s [status_code:%d, duration:%s, content_length:%d]",
-						req.Method, req.URL, writerInterceptor.StatusCode, duration, len(writerInterceptor.Body),
-					),
-					o.LevelFunc(writerInterceptor.StatusCode),
-					*currentLoggerContext.Slice()...,
-				)
+				if policy.BodyCaptureLimit > 0 {
+					body := writerInterceptor.Body
+					if len(body) > policy.BodyCaptureLimit {
+						body = body[:policy.BodyCaptureLimit]
+					}
+					finalContext.Add("http_response_body", policy.Sanitize(string(body)))
+				}
+
+				currentLogger.Log(exchange.Message(), level, *finalContext.Slice()...)
 			}()
 
-			currentLogger.Debug(fmt.Sprintf("http server received %s %s", req.Method, req.URL), *currentLoggerContext.Slice()...)
-			next.ServeHTTP(writerInterceptor.ResponseWriter, req)
+			policy.Observers.OnStart(exchange)
+			if sampled && policy.Enabled(currentLogger, logger.DebugLevel) {
+				currentLogger.Debug("http server received "+exchange.Method+" "+exchange.URL, *loggerContext().Slice()...)
+			}
+			if dump {
+				currentLogger.Log("http server request dump "+exchange.Method+" "+exchange.URL, policy.DumpLevel, append(*loggerContext().Slice(), logger.String("http_dump", policy.DumpRequest(req, requestDumpBody)))...)
+			}
+			responseWriter := writerInterceptor.ResponseWriter
+			if policy.Registry != nil {
+				responseWriter = newCountingResponseWriter(responseWriter, exchange)
+			}
+			defer policy.StartWatchdog(currentLogger, exchange, loggerContext)()
+			next.ServeHTTP(responseWriter, req)
 		})
 	}
 }
+
+// countingResponseWriter counts the bytes written so far for the Registry
+// use newCountingResponseWriter so the http.Flusher, http.Hijacker and http.Pusher of the wrapped writer stay visible
+// and are not advertised when the wrapped writer does not implement them
+type countingResponseWriter struct {
+	http.ResponseWriter
+	exchange *logger_http.Exchange
+}
+
+func newCountingResponseWriter(writer http.ResponseWriter, exchange *logger_http.Exchange) http.ResponseWriter {
+	countingWriter := &countingResponseWriter{ResponseWriter: writer, exchange: exchange}
+	flusher, isFlusher := writer.(http.Flusher)
+	hijacker, isHijacker := writer.(http.Hijacker)
+	pusher, isPusher := writer.(http.Pusher)
+	switch {
+	case isFlusher && isHijacker && isPusher:
+		return struct {
+			*countingResponseWriter
+			http.Flusher
+			http.Hijacker
+			http.Pusher
+		}{countingWriter, flusher, hijacker, pusher}
+	case isFlusher && isHijacker:
+		return struct {
+			*countingResponseWriter
+			http.Flusher
+			http.Hijacker
+		}{countingWriter, flusher, hijacker}
+	case isFlusher && isPusher:
+		return struct {
+			*countingResponseWriter
+			http.Flusher
+			http.Pusher
+		}{countingWriter, flusher, pusher}
+	case isHijacker && isPusher:
+		return struct {
+			*countingResponseWriter
+			http.Hijacker
+			http.Pusher
+		}{countingWriter, hijacker, pusher}
+	case isFlusher:
+		return struct {
+			*countingResponseWriter
+			http.Flusher
+		}{countingWriter, flusher}
+	case isHijacker:
+		return struct {
+			*countingResponseWriter
+			http.Hijacker
+		}{countingWriter, hijacker}
+	case isPusher:
+		return struct {
+			*countingResponseWriter
+			http.Pusher
+		}{countingWriter, pusher}
+	}
+	return countingWriter
+}
+
+func (w *countingResponseWriter) Write(b []byte) (int, error) {
+	n, err := w.ResponseWriter.Write(b)
+	w.exchange.AddBytesWritten(n)
+	return n, err
+}
+
+// ReadFrom keeps the sendfile optimization of the wrapped writer
+func (w *countingResponseWriter) ReadFrom(reader io.Reader) (int64, error) {
+	var n int64
+	var err error
+	if readerFrom, ok := w.ResponseWriter.(io.ReaderFrom); ok {
+		n, err = readerFrom.ReadFrom(reader)
+	} else {
+		n, err = io.Copy(w.ResponseWriter, reader)
+	}
+	w.exchange.AddBytesWritten(int(n))
+	return n, err
+}
+
+func (w *countingResponseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
@@ -0,0 +1,50 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gol4ng/logger"
+	logger_middleware "github.com/gol4ng/logger/middleware"
+
+	"github.com/gol4ng/logger-http"
+	"github.com/gol4ng/logger-http/middleware"
+)
+
+func benchmarkLogger(b *testing.B, l logger.LoggerInterface, opts ...logger_http.Option) {
+	h := http.HandlerFunc(func(writer http.ResponseWriter, innerRequest *http.Request) {
+		writer.WriteHeader(http.StatusNoContent)
+	})
+	handler := middleware.Logger(l, opts...)(h)
+	request := httptest.NewRequest(http.MethodGet, "http://127.0.0.1/my-fake-url", nil)
+	request.Header.Set("User-Agent", "benchmark")
+	writer := httptest.NewRecorder()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		handler.ServeHTTP(writer, request)
+	}
+}
+
+func BenchmarkLogger(b *testing.B) {
+	benchmarkLogger(b, logger.NewNopLogger())
+}
+
+func BenchmarkLogger_WithMinLevel(b *testing.B) {
+	benchmarkLogger(b, logger.NewNopLogger(), logger_http.WithMinLevel(logger.InfoLevel))
+}
+
+func BenchmarkLogger_Filtered(b *testing.B) {
+	benchmarkLogger(b, logger.NewNopLogger(), logger_http.WithMinLevel(logger.WarningLevel))
+}
+
+// the decorators cannot see the gol4ng handler filter, every entry is built then dropped
+func BenchmarkLogger_MinLevelFilter(b *testing.B) {
+	benchmarkLogger(b, logger.NewLogger(logger_middleware.MinLevelFilter(logger.WarningLevel)(logger.NopHandler)))
+}
+
+func BenchmarkLogger_MinLevelFilterWithMinLevel(b *testing.B) {
+	benchmarkLogger(b, logger.NewLogger(logger_middleware.MinLevelFilter(logger.WarningLevel)(logger.NopHandler)), logger_http.WithMinLevel(logger.WarningLevel))
+}
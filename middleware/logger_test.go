@@ -2,8 +2,13 @@ package middleware_test
 
 import (
 	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -12,6 +17,7 @@ import (
 	"github.com/stretchr/testify/assert"
 
 	"github.com/gol4ng/logger-http"
+	"github.com/gol4ng/logger-http/loggerhttptest"
 	"github.com/gol4ng/logger-http/middleware"
 )
 
@@ -50,6 +56,9 @@ func TestLogger(t *testing.T) {
 	assert.Equal(t, "OK", (*entry2.Context)["http_status"].Value)
 	assert.Equal(t, int64(200), (*entry2.Context)["http_status_code"].Value)
 	assert.Contains(t, *entry2.Context, "http_duration")
+	assert.Contains(t, *entry2.Context, "http_request_budget")
+	assert.Contains(t, *entry2.Context, "http_remaining_budget")
+	assert.Equal(t, false, (*entry2.Context)["http_deadline_exceeded"].Value)
 }
 
 func TestLogger_WithPanic(t *testing.T) {
@@ -128,6 +137,36 @@ func TestLogger_WithContext(t *testing.T) {
 	assert.Contains(t, *entry2.Context, "http_duration")
 }
 
+func TestLogger_WithAppendLoggerContext(t *testing.T) {
+	request := httptest.NewRequest(http.MethodGet, "http://127.0.0.1/my-fake-url", nil)
+	ctx, _ := context.WithTimeout(request.Context(), 3*time.Second)
+	request = request.WithContext(ctx)
+	responseWriter := &httptest.ResponseRecorder{}
+
+	h := http.HandlerFunc(func(writer http.ResponseWriter, innerRequest *http.Request) {
+		writer.Write([]byte(`OK`))
+	})
+
+	myLogger, store := testing_logger.NewLogger()
+
+	middleware.Logger(myLogger, logger_http.AppendLoggerContext(
+		logger_http.UserAgentContext,
+		func(request *http.Request) *logger.Context {
+			return logger.NewContext().Add("base_context_key", "base_context_value")
+		},
+	))(h).ServeHTTP(responseWriter, request)
+
+	entries := store.GetEntries()
+	assert.Len(t, entries, 2)
+
+	for _, entry := range entries {
+		AssertDefaultContextFields(t, entry)
+		assert.Equal(t, "base_context_value", (*entry.Context)["base_context_key"].Value)
+		assert.Equal(t, request.Header, (*entry.Context)["http_header"].Value)
+		assert.NotContains(t, *entry.Context, "http_user_agent")
+	}
+}
+
 func TestLogger_WithLevels(t *testing.T) {
 	request := httptest.NewRequest(http.MethodGet, "http://127.0.0.1/my-fake-url", nil)
 	ctx, _ := context.WithTimeout(request.Context(), 3*time.Second)
@@ -167,6 +206,574 @@ func TestLogger_WithLevels(t *testing.T) {
 	assert.Contains(t, *entry2.Context, "http_duration")
 }
 
+func TestLogger_ConcurrentSharedContext(t *testing.T) {
+	sharedContext := logger.NewContext().Add("service", "my-service")
+
+	h := http.HandlerFunc(func(writer http.ResponseWriter, innerRequest *http.Request) {
+		writer.Write([]byte(`OK`))
+	})
+
+	myLogger, store := testing_logger.NewLogger()
+	decoratedHandler := middleware.Logger(myLogger, logger_http.WithLoggerContext(func(request *http.Request) *logger.Context {
+		return sharedContext
+	}))(h)
+
+	wg := sync.WaitGroup{}
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			request := httptest.NewRequest(http.MethodGet, fmt.Sprintf("http://127.0.0.1/my-fake-url-%d", i), nil)
+			decoratedHandler.ServeHTTP(httptest.NewRecorder(), request)
+		}(i)
+	}
+	wg.Wait()
+
+	assert.Len(t, *sharedContext, 1)
+	entries := store.GetEntries()
+	assert.Len(t, entries, 100)
+	for _, entry := range entries {
+		assert.Equal(t, "my-service", (*entry.Context)["service"].Value)
+		assert.Contains(t, entry.Message, (*entry.Context)["http_url"].Value)
+	}
+}
+
+func TestLogger_WithRequestFilter(t *testing.T) {
+	h := http.HandlerFunc(func(writer http.ResponseWriter, innerRequest *http.Request) {
+		writer.Write([]byte(`OK`))
+	})
+
+	myLogger, store := testing_logger.NewLogger()
+	decoratedHandler := middleware.Logger(myLogger, logger_http.WithRequestFilter(logger_http.PathFilter("/health")))(h)
+
+	responseWriter := httptest.NewRecorder()
+	decoratedHandler.ServeHTTP(responseWriter, httptest.NewRequest(http.MethodGet, "http://127.0.0.1/health", nil))
+	assert.Equal(t, "OK", responseWriter.Body.String())
+	assert.Len(t, store.GetEntries(), 0)
+
+	decoratedHandler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "http://127.0.0.1/my-fake-url", nil))
+	assert.Len(t, store.GetEntries(), 2)
+}
+
+func TestLogger_WithRoutePolicy(t *testing.T) {
+	h := http.HandlerFunc(func(writer http.ResponseWriter, innerRequest *http.Request) {
+		body, _ := ioutil.ReadAll(innerRequest.Body)
+		assert.Equal(t, "my request body", string(body))
+		writer.WriteHeader(http.StatusInternalServerError)
+		writer.Write([]byte(`my response body`))
+	})
+
+	myLogger, store := testing_logger.NewLogger()
+	decoratedHandler := middleware.Logger(myLogger,
+		logger_http.WithRoutePolicy("/api",
+			logger_http.WithBodyCapture(10),
+			logger_http.WithRedactedHeaders("Authorization"),
+			logger_http.WithSampler(func(*http.Request) bool {
+				return false
+			}),
+		),
+	)(h)
+
+	request := httptest.NewRequest(http.MethodPost, "http://127.0.0.1/api/my-fake-url", strings.NewReader("my request body"))
+	ctx, cancel := context.WithTimeout(request.Context(), 3*time.Second)
+	defer cancel()
+	request = request.WithContext(ctx)
+	request.Header.Set("Authorization", "Bearer my-token")
+	decoratedHandler.ServeHTTP(httptest.NewRecorder(), request)
+
+	// not sampled: only the error entry is logged
+	entries := store.GetEntries()
+	assert.Len(t, entries, 1)
+
+	entry := entries[0]
+	AssertDefaultContextFields(t, entry)
+	assert.Equal(t, logger.ErrorLevel, entry.Level)
+	assert.Equal(t, "my request", (*entry.Context)["http_request_body"].Value)
+	assert.Equal(t, "my respons", (*entry.Context)["http_response_body"].Value)
+	assert.Equal(t, logger_http.RedactedValue, (*entry.Context)["http_header"].Value.(http.Header).Get("Authorization"))
+}
+
+func TestLogger_WithRedactedURL(t *testing.T) {
+	request := httptest.NewRequest(http.MethodGet, "http://127.0.0.1/my-fake-url?access_token=secret", nil)
+	ctx, cancel := context.WithTimeout(request.Context(), 3*time.Second)
+	defer cancel()
+	request = request.WithContext(ctx)
+
+	h := http.HandlerFunc(func(writer http.ResponseWriter, innerRequest *http.Request) {
+		assert.Equal(t, "secret", innerRequest.URL.Query().Get("access_token"))
+		writer.Write([]byte(`OK`))
+	})
+
+	myLogger, store := testing_logger.NewLogger()
+
+	middleware.Logger(myLogger, logger_http.WithRedactedQueryParams("access_token"))(h).ServeHTTP(httptest.NewRecorder(), request)
+
+	entries := store.GetEntries()
+	assert.Len(t, entries, 2)
+	for _, entry := range entries {
+		AssertDefaultContextFields(t, entry)
+		assert.Equal(t, "http://127.0.0.1/my-fake-url?access_token=[REDACTED]", (*entry.Context)["http_url"].Value)
+		assert.Contains(t, entry.Message, "http://127.0.0.1/my-fake-url?access_token=[REDACTED]")
+		assert.NotContains(t, entry.Message, "secret")
+	}
+}
+
+func TestLogger_WithLogInjection(t *testing.T) {
+	request := httptest.NewRequest(http.MethodGet, "http://127.0.0.1/my-fake-url", nil)
+	request.URL.RawQuery = "q=\r\n<info> forged entry"
+	request.Header.Set("X-Forged", "\x1b[31mvalue")
+	ctx, cancel := context.WithTimeout(request.Context(), 3*time.Second)
+	defer cancel()
+	request = request.WithContext(ctx)
+
+	h := http.HandlerFunc(func(writer http.ResponseWriter, innerRequest *http.Request) {
+		writer.Write([]byte(`OK`))
+	})
+
+	myLogger, store := testing_logger.NewLogger()
+
+	middleware.Logger(myLogger)(h).ServeHTTP(httptest.NewRecorder(), request)
+
+	entries := store.GetEntries()
+	assert.Len(t, entries, 2)
+	for _, entry := range entries {
+		AssertDefaultContextFields(t, entry)
+		assert.NotContains(t, entry.Message, "\n")
+		assert.Contains(t, entry.Message, `http://127.0.0.1/my-fake-url?q=\r\n<info> forged entry`)
+		assert.Equal(t, `http://127.0.0.1/my-fake-url?q=\r\n<info> forged entry`, (*entry.Context)["http_url"].Value)
+		assert.Equal(t, `\x1b[31mvalue`, (*entry.Context)["http_header"].Value.(http.Header).Get("X-Forged"))
+	}
+}
+
+func TestLogger_WithObservers(t *testing.T) {
+	request := httptest.NewRequest(http.MethodGet, "http://127.0.0.1/my-fake-url", nil)
+
+	h := http.HandlerFunc(func(writer http.ResponseWriter, innerRequest *http.Request) {
+		writer.WriteHeader(http.StatusCreated)
+		writer.Write([]byte(`OK`))
+	})
+
+	var started, ended *logger_http.Exchange
+	myLogger, _ := testing_logger.NewLogger()
+	middleware.Logger(myLogger, logger_http.WithObservers(logger_http.ObserverFuncs{
+		Start: func(exchange *logger_http.Exchange) {
+			assert.Equal(t, 0, exchange.StatusCode)
+			started = exchange
+		},
+		End: func(exchange *logger_http.Exchange) {
+			ended = exchange
+		},
+		Panic: func(exchange *logger_http.Exchange) {
+			assert.Fail(t, "panic must not be observed")
+		},
+	}))(h).ServeHTTP(httptest.NewRecorder(), request)
+
+	assert.Same(t, started, ended)
+	assert.Equal(t, "server", ended.Kind)
+	assert.Equal(t, "GET", ended.Method)
+	assert.Equal(t, "http://127.0.0.1/my-fake-url", ended.URL)
+	assert.Equal(t, http.StatusCreated, ended.StatusCode)
+	assert.Equal(t, "Created", ended.Status)
+	assert.Equal(t, int64(2), ended.ResponseLength)
+	assert.True(t, ended.Duration > 0)
+}
+
+func TestLogger_WithObservers_Panic(t *testing.T) {
+	request := httptest.NewRequest(http.MethodGet, "http://127.0.0.1/my-fake-url", nil)
+
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic("my handler panic")
+	})
+
+	var panicked *logger_http.Exchange
+	myLogger, _ := testing_logger.NewLogger()
+	assert.Panics(t, func() {
+		middleware.Logger(myLogger, logger_http.WithObservers(logger_http.ObserverFuncs{
+			End: func(exchange *logger_http.Exchange) {
+				assert.Fail(t, "end must not be observed")
+			},
+			Panic: func(exchange *logger_http.Exchange) {
+				panicked = exchange
+			},
+		}))(h).ServeHTTP(httptest.NewRecorder(), request)
+	})
+
+	assert.Equal(t, "my handler panic", panicked.Panic)
+}
+
+func TestLogger_WithClock(t *testing.T) {
+	clock := loggerhttptest.NewClock(time.Date(2019, 12, 13, 17, 1, 13, 0, time.UTC))
+
+	h := http.HandlerFunc(func(writer http.ResponseWriter, innerRequest *http.Request) {
+		clock.Add(1500 * time.Millisecond)
+		writer.Write([]byte(`OK`))
+	})
+
+	myLogger, store := testing_logger.NewLogger()
+
+	middleware.Logger(myLogger, logger_http.WithClock(clock))(h).ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "http://127.0.0.1/my-fake-url", nil))
+
+	entries := store.GetEntries()
+	assert.Len(t, entries, 2)
+
+	assert.Equal(t, "2019-12-13T17:01:13Z", (*entries[0].Context)["http_start_time"].Value)
+	assert.Equal(t, "2019-12-13T17:01:13Z", (*entries[1].Context)["http_start_time"].Value)
+	assert.Equal(t, 1.5, (*entries[1].Context)["http_duration"].Value)
+	assert.Equal(t, "http server GET http://127.0.0.1/my-fake-url [status_code:200, duration:1.5s, content_length:2]", entries[1].Message)
+}
+
+func TestLogger_WithDump(t *testing.T) {
+	h := http.HandlerFunc(func(writer http.ResponseWriter, innerRequest *http.Request) {
+		body, _ := ioutil.ReadAll(innerRequest.Body)
+		assert.Equal(t, "my request body", string(body))
+		writer.Header().Set("Set-Cookie", "session=secret")
+		writer.Write([]byte(`my response body`))
+	})
+
+	myLogger, store := testing_logger.NewLogger()
+	dumpSwitch := logger_http.NewDumpSwitch()
+	handler := middleware.Logger(myLogger,
+		logger_http.WithDump(dumpSwitch, 1024),
+		logger_http.WithRedactedHeaders("Set-Cookie"),
+	)(h)
+
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "http://127.0.0.1/api/users", strings.NewReader("my request body")))
+	assert.Len(t, store.GetEntries(), 2)
+
+	store.CleanEntries()
+	dumpSwitch.EnableRoute("/api", true)
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "http://127.0.0.1/api/users", strings.NewReader("my request body")))
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "http://127.0.0.1/other", strings.NewReader("my request body")))
+
+	entries := store.GetEntries()
+	assert.Len(t, entries, 6)
+	assert.Equal(t, logger.DebugLevel, entries[1].Level)
+	assert.Equal(t, "http server request dump POST http://127.0.0.1/api/users", entries[1].Message)
+	assert.Equal(t, `POST http://127.0.0.1/api/users HTTP/1.1\nContent-Length: 15\nHost: 127.0.0.1\n\nmy request body`, (*entries[1].Context)["http_dump"].Value)
+	assert.Equal(t, "http server response dump POST http://127.0.0.1/api/users", entries[2].Message)
+	assert.Equal(t, `HTTP/1.1 200 OK\nContent-Length: 16\nContent-Type: text/plain; charset=utf-8\nSet-Cookie: [REDACTED]\n\nmy response body`, (*entries[2].Context)["http_dump"].Value)
+	assert.Contains(t, entries[3].Message, "http server POST http://127.0.0.1/api/users [status_code:200, duration:")
+	assert.NotContains(t, *entries[5].Context, "http_dump")
+}
+
+func TestLogger_WithDebugToken(t *testing.T) {
+	key := []byte("my-key")
+	myLogger, store := testing_logger.NewLogger()
+	var contextToken string
+	h := http.HandlerFunc(func(writer http.ResponseWriter, innerRequest *http.Request) {
+		contextToken = logger_http.DebugTokenFromContext(innerRequest.Context())
+		logger.FromContext(innerRequest.Context(), myLogger).Debug("handler log debug")
+		writer.Write([]byte(`OK`))
+	})
+
+	handler := middleware.Logger(myLogger,
+		logger_http.WithDebugToken(key, "my-service", time.Hour),
+		logger_http.WithSampler(logger_http.RateSampler(0)),
+	)(h)
+
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "http://127.0.0.1/my-fake-url", strings.NewReader("my body")))
+	assert.Len(t, store.GetEntries(), 1)
+	assert.Equal(t, "handler log debug", store.GetEntries()[0].Message)
+	assert.NotContains(t, *store.GetEntries()[0].Context, logger_http.DebugFieldName)
+
+	store.CleanEntries()
+	request := httptest.NewRequest(http.MethodPost, "http://127.0.0.1/my-fake-url", strings.NewReader("my body"))
+	token := logger_http.NewDebugToken(key, "my-service", time.Now().Add(time.Minute))
+	request.Header.Set(logger_http.DefaultDebugHeader, token)
+	handler.ServeHTTP(httptest.NewRecorder(), request)
+	assert.Equal(t, token, contextToken)
+
+	entries := store.GetEntries()
+	assert.Len(t, entries, 5)
+	assert.Equal(t, "http server received POST http://127.0.0.1/my-fake-url", entries[0].Message)
+	assert.Equal(t, "http server request dump POST http://127.0.0.1/my-fake-url", entries[1].Message)
+	assert.Equal(t, "handler log debug", entries[2].Message)
+	assert.Equal(t, "http server response dump POST http://127.0.0.1/my-fake-url", entries[3].Message)
+	for _, entry := range entries {
+		assert.Equal(t, true, (*entry.Context)[logger_http.DebugFieldName].Value)
+	}
+	assert.Equal(t, "my body", (*entries[4].Context)["http_request_body"].Value)
+	assert.Equal(t, http.Header{logger_http.DefaultDebugHeader: {logger_http.RedactedValue}}, (*entries[4].Context)["http_header"].Value)
+}
+
+func TestLogger_WithDebugToken_DebugLevelFilter(t *testing.T) {
+	key := []byte("my-key")
+	_, store := testing_logger.NewLogger()
+	myLogger := logger.NewLogger(logger_http.DebugLevelFilter(logger.InfoLevel)(store.Handle))
+	h := http.HandlerFunc(func(writer http.ResponseWriter, innerRequest *http.Request) {
+		logger.FromContext(innerRequest.Context(), myLogger).Debug("handler log debug")
+		writer.Write([]byte(`OK`))
+	})
+	handler := middleware.Logger(myLogger, logger_http.WithDebugToken(key, "my-service", time.Hour))(h)
+
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "http://127.0.0.1/my-fake-url", nil))
+	entries := store.GetEntries()
+	assert.Len(t, entries, 1)
+	assert.Equal(t, logger.InfoLevel, entries[0].Level)
+
+	store.CleanEntries()
+	request := httptest.NewRequest(http.MethodGet, "http://127.0.0.1/my-fake-url", nil)
+	request.Header.Set(logger_http.DefaultDebugHeader, logger_http.NewDebugToken(key, "my-service", time.Now().Add(time.Minute)))
+	handler.ServeHTTP(httptest.NewRecorder(), request)
+
+	entries = store.GetEntries()
+	assert.Len(t, entries, 5)
+	assert.Equal(t, "http server received GET http://127.0.0.1/my-fake-url", entries[0].Message)
+	assert.Equal(t, "handler log debug", entries[2].Message)
+	assert.Equal(t, logger.DebugLevel, entries[2].Level)
+
+	store.CleanEntries()
+	request.Header.Set(logger_http.DefaultDebugHeader, logger_http.NewDebugToken(key, "other-service", time.Now().Add(time.Minute)))
+	handler.ServeHTTP(httptest.NewRecorder(), request)
+	assert.Len(t, store.GetEntries(), 1)
+}
+
+func TestLogger_WithFieldNames(t *testing.T) {
+	h := http.HandlerFunc(func(writer http.ResponseWriter, innerRequest *http.Request) {
+		writer.WriteHeader(http.StatusCreated)
+	})
+
+	myLogger, store := testing_logger.NewLogger()
+	middleware.Logger(myLogger, logger_http.WithFieldNames(logger_http.OTelFieldNames))(h).ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "http://127.0.0.1/my-fake-url", nil))
+
+	entries := store.GetEntries()
+	assert.Len(t, entries, 2)
+	assert.Equal(t, "GET", (*entries[1].Context)["http.request.method"].Value)
+	assert.Equal(t, "http://127.0.0.1/my-fake-url", (*entries[1].Context)["url.full"].Value)
+	assert.EqualValues(t, http.StatusCreated, (*entries[1].Context)["http.response.status_code"].Value)
+	assert.NotContains(t, *entries[1].Context, "http_method")
+}
+
+func TestLogger_WithQueueDuration(t *testing.T) {
+	clock := loggerhttptest.NewClock(time.Date(2019, 12, 13, 16, 1, 13, 0, time.UTC))
+	h := http.HandlerFunc(func(writer http.ResponseWriter, innerRequest *http.Request) {
+		clock.Add(time.Second)
+	})
+
+	myLogger, store := testing_logger.NewLogger()
+	request := httptest.NewRequest(http.MethodGet, "http://127.0.0.1/my-fake-url", nil)
+	request.Header.Set("X-Request-Start", "t=1576252872500000")
+	middleware.Logger(myLogger, logger_http.WithClock(clock))(h).ServeHTTP(httptest.NewRecorder(), request)
+
+	entries := store.GetEntries()
+	assert.Len(t, entries, 2)
+	assert.Equal(t, 0.5, (*entries[0].Context)["http_queue_duration"].Value)
+	assert.Equal(t, 0.5, (*entries[1].Context)["http_queue_duration"].Value)
+	assert.Equal(t, 1.0, (*entries[1].Context)["http_duration"].Value)
+}
+
+func TestLogger_WithAsyncEmitter(t *testing.T) {
+	h := http.HandlerFunc(func(writer http.ResponseWriter, innerRequest *http.Request) {
+		writer.Write([]byte(`OK`))
+	})
+
+	myLogger, store := testing_logger.NewLogger()
+	emitter := logger_http.NewAsyncEmitter(10, 1, logger_http.BlockOnFull)
+	defer emitter.Close(context.Background())
+
+	middleware.Logger(myLogger, logger_http.WithAsyncEmitter(emitter))(h).ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "http://127.0.0.1/my-fake-url", nil))
+	assert.Nil(t, emitter.Flush(context.Background()))
+
+	entries := store.GetEntries()
+	assert.Len(t, entries, 2)
+	assert.Equal(t, "http server received GET http://127.0.0.1/my-fake-url", entries[0].Message)
+	assert.Equal(t, int64(200), (*entries[1].Context)["http_status_code"].Value)
+}
+
+func TestLogger_WithMinLevel(t *testing.T) {
+	h := http.HandlerFunc(func(writer http.ResponseWriter, innerRequest *http.Request) {
+		writer.Write([]byte(`OK`))
+	})
+
+	ended := 0
+	myLogger, store := testing_logger.NewLogger()
+	handler := middleware.Logger(myLogger,
+		logger_http.WithMinLevel(logger.InfoLevel),
+		logger_http.WithObservers(logger_http.ObserverFuncs{End: func(exchange *logger_http.Exchange) { ended++ }}),
+	)(h)
+
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "http://127.0.0.1/my-fake-url", nil))
+	entries := store.GetEntries()
+	assert.Len(t, entries, 1)
+	assert.Equal(t, logger.InfoLevel, entries[0].Level)
+	assert.Equal(t, "GET", (*entries[0].Context)["http_method"].Value)
+
+	store.CleanEntries()
+	handler = middleware.Logger(myLogger,
+		logger_http.WithMinLevel(logger.WarningLevel),
+		logger_http.WithObservers(logger_http.ObserverFuncs{End: func(exchange *logger_http.Exchange) { ended++ }}),
+	)(h)
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "http://127.0.0.1/my-fake-url", nil))
+	assert.Empty(t, store.GetEntries())
+	assert.Equal(t, 2, ended)
+}
+
+func TestLogger_WithTailBuffer(t *testing.T) {
+	clock := loggerhttptest.NewClock(time.Date(2019, 12, 13, 17, 1, 13, 0, time.UTC))
+	h := http.HandlerFunc(func(writer http.ResponseWriter, innerRequest *http.Request) {
+		innerLogger := logger.FromContext(innerRequest.Context(), nil)
+		innerLogger.Debug("handler debug")
+		innerLogger.Warning("handler warning")
+		switch innerRequest.URL.Path {
+		case "/error":
+			writer.WriteHeader(http.StatusInternalServerError)
+		case "/slow":
+			clock.Add(2 * time.Second)
+		case "/panic":
+			panic("my handler panic")
+		}
+	})
+
+	myLogger, store := testing_logger.NewLogger()
+	handler := middleware.Logger(myLogger, logger_http.WithClock(clock), logger_http.WithTailBuffer(10, time.Second))(h)
+
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "http://127.0.0.1/ok", nil))
+	entries := store.GetEntries()
+	assert.Len(t, entries, 3)
+	assert.Equal(t, "http server received GET http://127.0.0.1/ok", entries[0].Message)
+	assert.Equal(t, "handler warning", entries[1].Message)
+	assert.Equal(t, logger.InfoLevel, entries[2].Level)
+
+	for _, path := range []string{"/error", "/slow", "/panic"} {
+		t.Run(path, func(t *testing.T) {
+			store.CleanEntries()
+			startTime := clock.Now()
+			func() {
+				defer func() { recover() }()
+				handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "http://127.0.0.1"+path, nil))
+			}()
+			entries := store.GetEntries()
+			assert.Len(t, entries, 4)
+			assert.Equal(t, "handler warning", entries[1].Message)
+			assert.Equal(t, "handler debug", entries[2].Message)
+			assert.Equal(t, startTime, (*entries[2].Context)[logger_http.TimestampFieldName].Value)
+		})
+	}
+}
+
+func TestLogger_WithRegistry(t *testing.T) {
+	registry := logger_http.NewRegistry(10, nil)
+	h := http.HandlerFunc(func(writer http.ResponseWriter, innerRequest *http.Request) {
+		writer.Write([]byte(`partial`))
+		inFlight := registry.InFlight()
+		assert.Len(t, inFlight, 1)
+		assert.Equal(t, "http://127.0.0.1/my-fake-url", inFlight[0].URL)
+		assert.Equal(t, "my-correlation-id", inFlight[0].CorrelationId)
+		assert.Equal(t, int64(7), inFlight[0].BytesWritten)
+		writer.Write([]byte(` response`))
+	})
+
+	request := httptest.NewRequest(http.MethodGet, "http://127.0.0.1/my-fake-url", nil)
+	request.Header.Set("Correlation-Id", "my-correlation-id")
+	recorder := httptest.NewRecorder()
+	middleware.Logger(logger.NewNopLogger(), logger_http.WithRegistry(registry))(h).ServeHTTP(recorder, request)
+
+	assert.Equal(t, "partial response", recorder.Body.String())
+	assert.Empty(t, registry.InFlight())
+	completed := registry.Completed()
+	assert.Len(t, completed, 1)
+	assert.Equal(t, http.StatusOK, completed[0].StatusCode)
+	assert.Equal(t, int64(16), completed[0].BytesWritten)
+}
+
+// plainResponseWriter hides the optional interfaces of the wrapped writer
+type plainResponseWriter struct {
+	http.ResponseWriter
+}
+
+func TestLogger_WithRegistry_Flusher(t *testing.T) {
+	var isFlusher bool
+	h := http.HandlerFunc(func(writer http.ResponseWriter, innerRequest *http.Request) {
+		writer.Write([]byte(`my response`))
+		var flusher http.Flusher
+		if flusher, isFlusher = writer.(http.Flusher); isFlusher {
+			flusher.Flush()
+		}
+	})
+	handler := middleware.Logger(logger.NewNopLogger(), logger_http.WithRegistry(logger_http.NewRegistry(10, nil)))(h)
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "http://127.0.0.1/my-fake-url", nil))
+	assert.True(t, isFlusher)
+	assert.True(t, recorder.Flushed)
+
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(plainResponseWriter{recorder}, httptest.NewRequest(http.MethodGet, "http://127.0.0.1/my-fake-url", nil))
+	assert.False(t, isFlusher)
+	assert.False(t, recorder.Flushed)
+}
+
+func TestLogger_WithRegistry_Hijacker(t *testing.T) {
+	registry := logger_http.NewRegistry(10, nil)
+	var isHijacker bool
+	h := http.HandlerFunc(func(writer http.ResponseWriter, innerRequest *http.Request) {
+		_, isHijacker = writer.(http.Hijacker)
+		io.Copy(writer, strings.NewReader(`my response`))
+	})
+	server := httptest.NewServer(middleware.Logger(logger.NewNopLogger(), logger_http.WithRegistry(registry))(h))
+	defer server.Close()
+
+	response, err := http.Get(server.URL)
+	assert.Nil(t, err)
+	body, _ := ioutil.ReadAll(response.Body)
+	response.Body.Close()
+
+	assert.True(t, isHijacker)
+	assert.Equal(t, "my response", string(body))
+	assert.Equal(t, int64(11), registry.Completed()[0].BytesWritten)
+}
+
+// warningFieldsRecorder keeps the field names given to Warning, duplicates included
+type warningFieldsRecorder struct {
+	logger.LoggerInterface
+	mutex sync.Mutex
+	names [][]string
+}
+
+func (r *warningFieldsRecorder) Warning(message string, fields ...logger.Field) {
+	names := make([]string, 0, len(fields))
+	for _, field := range fields {
+		names = append(names, field.Name)
+	}
+	r.mutex.Lock()
+	r.names = append(r.names, names)
+	r.mutex.Unlock()
+	r.LoggerInterface.Warning(message, fields...)
+}
+
+func TestLogger_WithWatchdog(t *testing.T) {
+	myLogger, store := testing_logger.NewLogger()
+	recorder := &warningFieldsRecorder{LoggerInterface: myLogger}
+	clock := loggerhttptest.NewClock(time.Date(2019, 12, 13, 17, 1, 13, 0, time.UTC))
+	h := http.HandlerFunc(func(writer http.ResponseWriter, innerRequest *http.Request) {
+		clock.Add(20 * time.Second)
+		assert.Eventually(t, func() bool { return len(store.GetEntries()) == 2 }, time.Second, time.Millisecond)
+	})
+
+	middleware.Logger(recorder, logger_http.WithClock(clock), logger_http.WithWatchdog(20*time.Second, 0))(h).ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "http://127.0.0.1/my-fake-url", nil))
+	clock.Add(time.Hour)
+
+	entries := store.GetEntries()
+	assert.Len(t, entries, 3)
+	warning := entries[1]
+	assert.Equal(t, logger.WarningLevel, warning.Level)
+	assert.Equal(t, "http server still running GET http://127.0.0.1/my-fake-url [duration:20s]", warning.Message)
+	assert.Contains(t, (*warning.Context)["http_stack"].Value, "TestLogger_WithWatchdog")
+	assert.NotContains(t, (*warning.Context)["http_stack"].Value, "\n")
+	assert.Equal(t, "server", (*warning.Context)["http_kind"].Value)
+	assert.Contains(t, *warning.Context, "http_header")
+	// the final entry is logged after the watchdog stopped
+	assert.Equal(t, logger.InfoLevel, entries[2].Level)
+
+	// each field is given once to the logger
+	assert.Len(t, recorder.names, 1)
+	seen := map[string]bool{}
+	for _, name := range recorder.names[0] {
+		assert.False(t, seen[name], "duplicate field %s", name)
+		seen[name] = true
+	}
+}
+
 func AssertDefaultContextFields(t *testing.T, entry logger.Entry) {
 	assert.Equal(t, "server", (*entry.Context)["http_kind"].Value)
 	assert.Contains(t, *entry.Context, "http_method")
@@ -0,0 +1,65 @@
+//go:build go1.21
+// +build go1.21
+
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+
+	"github.com/gol4ng/httpware/v4"
+	"github.com/gol4ng/httpware/v4/correlation_id"
+	http_middleware "github.com/gol4ng/httpware/v4/middleware"
+	"github.com/gol4ng/logger"
+
+	logger_http "github.com/gol4ng/logger-http"
+)
+
+// SlogLogger will decorate the http.Handler to log with the *slog.Logger from the request context or the given one
+// it emits the same messages and fields as Logger
+func SlogLogger(log *slog.Logger, opts ...logger_http.Option) httpware.Middleware {
+	return newLogger(func(ctx context.Context) logger.LoggerInterface {
+		return logger_http.NewSlogBridge(ctx, logger_http.SlogFromContext(ctx, log))
+	}, opts...)
+}
+
+// SlogInjectLogger will inject the *slog.Logger on request context if not exist
+// prefer to use http.Server BaseContext as explained in InjectLogger
+func SlogInjectLogger(log *slog.Logger) httpware.Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(writer http.ResponseWriter, req *http.Request) {
+			ctx := req.Context()
+			if logger_http.SlogFromContext(ctx, nil) == nil {
+				req = req.WithContext(logger_http.InjectSlogInContext(ctx, log))
+			}
+			next.ServeHTTP(writer, req)
+		})
+	}
+}
+
+// SlogCorrelationId is the slog equivalent of CorrelationId
+// it will add correlationId to the *slog.Logger of the request context
+// eg:
+//
+//	stack := httpware.MiddlewareStack(
+//		middleware.SlogInjectLogger(l), // << Inject logger before SlogCorrelationId
+//		middleware.SlogCorrelationId(),
+//	)
+func SlogCorrelationId(options ...correlation_id.Option) httpware.Middleware {
+	warning := logger_http.MessageWithFileLine("correlationId need a slog logger", 1)
+	config := correlation_id.GetConfig(options...)
+	orig := http_middleware.CorrelationId(options...)
+	return func(next http.Handler) http.Handler {
+		return orig(http.HandlerFunc(func(writer http.ResponseWriter, req *http.Request) {
+			ctx := req.Context()
+			if requestLogger := logger_http.SlogFromContext(ctx, nil); requestLogger != nil {
+				req = req.WithContext(logger_http.InjectSlogInContext(ctx, requestLogger.With(config.HeaderName, ctx.Value(config.HeaderName))))
+			} else {
+				fmt.Println(warning)
+			}
+			next.ServeHTTP(writer, req)
+		}))
+	}
+}
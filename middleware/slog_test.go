@@ -0,0 +1,97 @@
+//go:build go1.21
+// +build go1.21
+
+package middleware_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gol4ng/httpware/v4"
+	"github.com/gol4ng/httpware/v4/correlation_id"
+	testing_logger "github.com/gol4ng/logger/testing"
+	"github.com/stretchr/testify/assert"
+
+	logger_http "github.com/gol4ng/logger-http"
+	"github.com/gol4ng/logger-http/middleware"
+)
+
+func TestSlogLogger(t *testing.T) {
+	h := http.HandlerFunc(func(writer http.ResponseWriter, innerRequest *http.Request) {
+		writer.Write([]byte(`OK`))
+	})
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+	newRequest := func() *http.Request {
+		return httptest.NewRequest(http.MethodGet, "http://127.0.0.1/my-fake-url", nil).WithContext(ctx)
+	}
+
+	buffer := &bytes.Buffer{}
+	mySlogLogger := slog.New(slog.NewJSONHandler(buffer, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	middleware.SlogLogger(mySlogLogger)(h).ServeHTTP(httptest.NewRecorder(), newRequest())
+
+	myLogger, store := testing_logger.NewLogger()
+	middleware.Logger(myLogger)(h).ServeHTTP(httptest.NewRecorder(), newRequest())
+
+	lines := strings.Split(strings.TrimSpace(buffer.String()), "\n")
+	entries := store.GetEntries()
+	assert.Len(t, lines, 2)
+	assert.Len(t, entries, 2)
+
+	for i, line := range lines {
+		slogEntry := map[string]interface{}{}
+		assert.Nil(t, json.Unmarshal([]byte(line), &slogEntry))
+		assert.Equal(t, entries[i].Message[:20], slogEntry["msg"].(string)[:20])
+		for name := range *entries[i].Context {
+			assert.Contains(t, slogEntry, name)
+		}
+		// time, level and msg are added by slog
+		assert.Len(t, slogEntry, len(*entries[i].Context)+3)
+		assert.Equal(t, logger_http.SlogLevel(entries[i].Level).String(), slogEntry["level"])
+	}
+}
+
+func TestSlogInjectLogger(t *testing.T) {
+	myLogger := slog.New(slog.NewJSONHandler(&bytes.Buffer{}, nil))
+	myLogger2 := slog.New(slog.NewJSONHandler(&bytes.Buffer{}, nil))
+
+	h := func(writer http.ResponseWriter, innerRequest *http.Request) {
+		assert.Same(t, myLogger, logger_http.SlogFromContext(innerRequest.Context(), nil))
+	}
+
+	httpware.MiddlewareStack(
+		middleware.SlogInjectLogger(myLogger),
+		middleware.SlogInjectLogger(myLogger2), // this middleware not inject logger because logger already injected
+	).DecorateHandlerFunc(h).ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "http://fake-addr", nil))
+}
+
+func TestSlogCorrelationId(t *testing.T) {
+	correlation_id.DefaultIdGenerator = correlation_id.NewRandomIdGenerator(
+		rand.New(correlation_id.NewLockedSource(rand.NewSource(1))),
+	)
+
+	buffer := &bytes.Buffer{}
+	myLogger := slog.New(slog.NewJSONHandler(buffer, nil))
+
+	h := func(writer http.ResponseWriter, innerRequest *http.Request) {
+		logger_http.SlogFromContext(innerRequest.Context(), nil).Info("handler info log")
+	}
+
+	httpware.MiddlewareStack(
+		middleware.SlogInjectLogger(myLogger),
+		middleware.SlogCorrelationId(),
+	).DecorateHandlerFunc(h).ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "http://fake-addr", nil))
+
+	entry := map[string]interface{}{}
+	assert.Nil(t, json.Unmarshal(buffer.Bytes(), &entry))
+	assert.Equal(t, "handler info log", entry["msg"])
+	assert.Equal(t, "p1LGIehp1s", entry["Correlation-Id"])
+}
@@ -3,6 +3,7 @@ package logger_http
 import (
 	"context"
 	"net/http"
+	"regexp"
 	"time"
 
 	"github.com/gol4ng/logger"
@@ -11,6 +12,46 @@ import (
 type Options struct {
 	LoggerContextProvider LoggerContextProvider
 	LevelFunc             CodeToLevel
+	RequestFilter         RequestFilter
+	Sampler               Sampler
+	BodyCaptureLimit      int
+	RedactedHeaders       []string
+	RedactedQueryParams   []string
+	RedactedPathSegments  []*regexp.Regexp
+	StripUserinfo         bool
+	MaxFieldLength        int
+	Observers             Observers
+	CorrelationIdHeader   string
+	Clock                 Clock
+	Curl                  bool
+	CurlStatusRanges      []StatusRange
+	Dump                  *DumpSwitch
+	DumpLevel             logger.Level
+	DumpBodyLimit         int
+	DebugKey              []byte
+	DebugAudience         string
+	DebugMaxTTL           time.Duration
+	DebugHeader           string
+	DebugBodyLimit        int
+	DebugForwardHosts     []string
+	Live                  *LiveConfig
+	FieldNames            map[string]string
+	BudgetHeader          string
+	MaxBudget             time.Duration
+	QueueHeaders          []string
+	MaxQueueDuration      time.Duration
+	Emitter               *AsyncEmitter
+	MinLevel              logger.Level
+	TailBufferLimit       int
+	TailSlowDuration      time.Duration
+	Registry              *Registry
+	WatchdogThreshold     time.Duration
+	WatchdogInterval      time.Duration
+	watchdog              *watchdog
+
+	route         string
+	routePolicies []routePolicy
+	routes        []route
 }
 
 // LoggerContextProvider function defines the default logger context values
@@ -21,9 +62,17 @@ type CodeToLevel func(statusCode int) logger.Level
 
 func newDefaultOptions() *Options {
 	return &Options{
-		LoggerContextProvider: func(request *http.Request) *logger.Context {
-			return logger.NewContext().Add("http_header", request.Header)
-		},
+		LoggerContextProvider: HeaderContext,
+		CorrelationIdHeader:   "Correlation-Id",
+		Clock:                 RealClock,
+		DumpLevel:             logger.DebugLevel,
+		MinLevel:              logger.DebugLevel,
+		DebugHeader:           DefaultDebugHeader,
+		DebugBodyLimit:        DefaultDebugBodyLimit,
+		BudgetHeader:          DefaultBudgetHeader,
+		MaxBudget:             DefaultMaxBudget,
+		QueueHeaders:          []string{"X-Request-Start", "X-Queue-Start"},
+		MaxQueueDuration:      DefaultMaxQueueDuration,
 		LevelFunc: func(statusCode int) logger.Level {
 			switch {
 			case statusCode < http.StatusBadRequest:
@@ -38,18 +87,10 @@ func newDefaultOptions() *Options {
 
 func EvaluateClientOpt(opts ...Option) *Options {
 	optCopy := newDefaultOptions()
-	baseOptCopy := optCopy.LoggerContextProvider
-	optCopy.LoggerContextProvider = func(request *http.Request) *logger.Context {
-		ctx := baseOptCopy(request)
-		// TODO
-		//if reader, err := request.GetBody(); err == nil {
-		//	ctx.Add("http_body", reader.Read())
-		//}
-		return ctx
-	}
 	for _, o := range opts {
 		o(optCopy)
 	}
+	optCopy.compile()
 	return optCopy
 }
 
@@ -58,18 +99,36 @@ func EvaluateServerOpt(opts ...Option) *Options {
 	for _, o := range opts {
 		o(optCopy)
 	}
+	optCopy.compile()
 	return optCopy
 }
 
 type Option func(*Options)
 
 // WithLoggerContext will provide default logger context values
+// it replaces the current provider, use AppendLoggerContext or PrependLoggerContext to compose with it
 func WithLoggerContext(f LoggerContextProvider) Option {
 	return func(o *Options) {
 		o.LoggerContextProvider = f
 	}
 }
 
+// AppendLoggerContext will add providers after the current one
+// appended providers win on conflicting keys
+func AppendLoggerContext(providers ...LoggerContextProvider) Option {
+	return func(o *Options) {
+		o.LoggerContextProvider = ChainLoggerContext(append([]LoggerContextProvider{o.LoggerContextProvider}, providers...)...)
+	}
+}
+
+// PrependLoggerContext will add providers before the current one
+// the current provider wins on conflicting keys
+func PrependLoggerContext(providers ...LoggerContextProvider) Option {
+	return func(o *Options) {
+		o.LoggerContextProvider = ChainLoggerContext(append(append([]LoggerContextProvider{}, providers...), o.LoggerContextProvider)...)
+	}
+}
+
 // WithLevels customizes the function for the mapping between http.StatusCode and logger.Level
 func WithLevels(f CodeToLevel) Option {
 	return func(o *Options) {
@@ -77,9 +136,43 @@ func WithLevels(f CodeToLevel) Option {
 	}
 }
 
-func FeedContext(loggerContext *logger.Context, ctx context.Context, req *http.Request, startTime time.Time) *logger.Context {
-	if loggerContext == nil {
-		loggerContext = logger.NewContext()
+// WithRequestFilter will skip logging for every request matching the filter
+func WithRequestFilter(f RequestFilter) Option {
+	return func(o *Options) {
+		o.RequestFilter = f
+	}
+}
+
+// WithSampler will only log the sampled requests
+// requests that are not sampled are still logged when their level is warning or above
+func WithSampler(s Sampler) Option {
+	return func(o *Options) {
+		o.Sampler = s
+	}
+}
+
+// WithBodyCapture will add up to limit bytes of the request and response bodies to the logger context
+// a limit lower or equal to 0 disables the capture
+func WithBodyCapture(limit int) Option {
+	return func(o *Options) {
+		o.BodyCaptureLimit = limit
+	}
+}
+
+// WithRedactedHeaders will replace the given header values in http_header
+func WithRedactedHeaders(headers ...string) Option {
+	return func(o *Options) {
+		o.RedactedHeaders = append(append([]string{}, o.RedactedHeaders...), headers...)
+	}
+}
+
+// FeedContext will return a new logger context filled with the request values
+// the given logger context is copied and never modified, so providers can safely return a shared context
+// the returned context comes from a pool, it can be given back with ReleaseContext once logged
+func FeedContext(baseContext *logger.Context, ctx context.Context, req *http.Request, startTime time.Time) *logger.Context {
+	loggerContext := acquireContext()
+	if baseContext != nil {
+		loggerContext.Merge(*baseContext)
 	}
 	loggerContext.
 		Add("http_method", req.Method).
@@ -87,7 +180,9 @@ func FeedContext(loggerContext *logger.Context, ctx context.Context, req *http.R
 		Add("http_start_time", startTime.Format(time.RFC3339))
 
 	if d, ok := ctx.Deadline(); ok {
-		loggerContext.Add("http_request_deadline", d.Format(time.RFC3339))
+		loggerContext.
+			Add("http_request_deadline", d.Format(time.RFC3339Nano)).
+			Add("http_request_budget", d.Sub(startTime).Seconds())
 	}
 	return loggerContext
 }
@@ -0,0 +1,55 @@
+package logger_http_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gol4ng/logger"
+	"github.com/stretchr/testify/assert"
+
+	logger_http "github.com/gol4ng/logger-http"
+)
+
+func TestFeedContext(t *testing.T) {
+	request := httptest.NewRequest(http.MethodGet, "http://127.0.0.1/my-fake-url", nil)
+	ctx, cancel := context.WithTimeout(request.Context(), 3*time.Second)
+	defer cancel()
+
+	baseContext := logger.NewContext().Add("base_context_key", "base_context_value")
+	loggerContext := logger_http.FeedContext(baseContext, ctx, request, time.Now())
+
+	assert.Len(t, *baseContext, 1)
+	assert.Equal(t, "base_context_value", (*loggerContext)["base_context_key"].Value)
+	assert.Equal(t, "GET", (*loggerContext)["http_method"].Value)
+	assert.Equal(t, "http://127.0.0.1/my-fake-url", (*loggerContext)["http_url"].Value)
+	assert.Contains(t, *loggerContext, "http_start_time")
+	assert.Contains(t, *loggerContext, "http_request_deadline")
+
+	loggerContext.Add("http_status_code", 200)
+	assert.NotContains(t, *baseContext, "http_status_code")
+}
+
+func TestFeedContext_NilContext(t *testing.T) {
+	request := httptest.NewRequest(http.MethodGet, "http://127.0.0.1/my-fake-url", nil)
+
+	loggerContext := logger_http.FeedContext(nil, request.Context(), request, time.Now())
+
+	assert.Equal(t, "GET", (*loggerContext)["http_method"].Value)
+	assert.NotContains(t, *loggerContext, "http_request_deadline")
+	assert.NotContains(t, *loggerContext, "http_request_budget")
+}
+
+func TestFeedContext_Budget(t *testing.T) {
+	request := httptest.NewRequest(http.MethodGet, "http://127.0.0.1/my-fake-url", nil)
+	startTime := time.Date(2019, 12, 13, 17, 1, 13, 0, time.UTC)
+	ctx, cancel := context.WithDeadline(request.Context(), startTime.Add(1500*time.Millisecond))
+	defer cancel()
+
+	loggerContext := logger_http.FeedContext(nil, ctx, request, startTime)
+
+	assert.Equal(t, "2019-12-13T17:01:14.5Z", (*loggerContext)["http_request_deadline"].Value)
+	assert.Equal(t, 1.5, (*loggerContext)["http_request_budget"].Value)
+}
@@ -0,0 +1,146 @@
+package logger_http
+
+import (
+	"math/rand"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/gol4ng/logger"
+)
+
+// RedactedValue is the value used in place of redacted data
+const RedactedValue = "[REDACTED]"
+
+// RequestFilter function returns true when the request must not be logged
+type RequestFilter func(*http.Request) bool
+
+// Sampler function returns true when the request must be logged
+type Sampler func(*http.Request) bool
+
+// PathFilter will filter requests with exactly one of the given paths
+// eg: WithRequestFilter(PathFilter("/health", "/metrics"))
+func PathFilter(paths ...string) RequestFilter {
+	return func(request *http.Request) bool {
+		for _, path := range paths {
+			if request.URL.Path == path {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// RateSampler will sample the given rate of requests (between 0 and 1)
+func RateSampler(rate float64) Sampler {
+	return func(request *http.Request) bool {
+		return rand.Float64() < rate
+	}
+}
+
+// WithRoutePolicy will override options for every request path under pathPrefix
+// prefixes match on path segments, "/api" matches "/api" and "/api/users" but not "/apiary"
+// route options are applied on top of the other options, the longest matching prefix wins
+// eg:
+//
+//	WithRoutePolicy("/metrics", WithRequestFilter(func(*http.Request) bool { return true }))
+//	WithRoutePolicy("/api/upload", WithBodyCapture(0), WithSampler(RateSampler(0.1)))
+func WithRoutePolicy(pathPrefix string, opts ...Option) Option {
+	return func(o *Options) {
+		o.routePolicies = append(o.routePolicies, routePolicy{pathPrefix: pathPrefix, options: opts})
+	}
+}
+
+// Policy will return the options that apply to the given request
+// live configuration overrides are applied on top of the route policy
+func (o *Options) Policy(request *http.Request) *Options {
+	policy := o
+	for _, r := range o.routes {
+		if MatchPathPrefix(request.URL.Path, r.pathPrefix) {
+			policy = r.options
+			break
+		}
+	}
+	if o.Live != nil {
+		return o.Live.apply(policy, request)
+	}
+	return policy
+}
+
+// MatchPathPrefix will return true when path is pathPrefix or one of its sub paths
+// a pathPrefix ending with "/" matches every path starting with it
+func MatchPathPrefix(path string, pathPrefix string) bool {
+	if !strings.HasPrefix(path, pathPrefix) {
+		return false
+	}
+	return len(path) == len(pathPrefix) || strings.HasSuffix(pathPrefix, "/") || path[len(pathPrefix)] == '/'
+}
+
+type routePolicy struct {
+	pathPrefix string
+	options    []Option
+}
+
+type route struct {
+	pathPrefix string
+	options    *Options
+}
+
+func (o *Options) compile() {
+	routes := make([]route, 0, len(o.routePolicies))
+	for _, policy := range o.routePolicies {
+		routeOptions := *o
+		routeOptions.route = policy.pathPrefix
+		routeOptions.routePolicies = nil
+		for _, opt := range policy.options {
+			opt(&routeOptions)
+		}
+		routeOptions.compile()
+		routes = append(routes, route{pathPrefix: policy.pathPrefix, options: &routeOptions})
+	}
+	sort.SliceStable(routes, func(i, j int) bool {
+		return len(routes[i].pathPrefix) > len(routes[j].pathPrefix)
+	})
+	o.routes = routes
+	if len(o.DebugKey) > 0 {
+		o.RedactedHeaders = append(append([]string{}, o.RedactedHeaders...), o.DebugHeader)
+	}
+	o.LoggerContextProvider = sanitizeContext(redactHeaderContext(o.LoggerContextProvider, o.RedactedHeaders), o.MaxFieldLength)
+}
+
+func redactHeaderContext(provider LoggerContextProvider, headers []string) LoggerContextProvider {
+	if len(headers) == 0 || provider == nil {
+		return provider
+	}
+	return func(request *http.Request) *logger.Context {
+		ctx := provider(request)
+		if ctx == nil {
+			return nil
+		}
+		field, ok := (*ctx)["http_header"]
+		if !ok {
+			return ctx
+		}
+		header, ok := field.Value.(http.Header)
+		if !ok {
+			return ctx
+		}
+		return logger.NewContext().Merge(*ctx).Add("http_header", RedactHeader(header, headers...))
+	}
+}
+
+// RedactHeader will return a copy of the header with the given header values redacted
+func RedactHeader(header http.Header, names ...string) http.Header {
+	redacted := header.Clone()
+	for _, name := range names {
+		name = http.CanonicalHeaderKey(name)
+		if values, ok := redacted[name]; ok {
+			redactedValues := make([]string, len(values))
+			for i := range values {
+				redactedValues[i] = RedactedValue
+			}
+			redacted[name] = redactedValues
+		}
+	}
+	return redacted
+}
@@ -0,0 +1,91 @@
+package logger_http_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gol4ng/logger"
+	"github.com/stretchr/testify/assert"
+
+	logger_http "github.com/gol4ng/logger-http"
+)
+
+func TestPathFilter(t *testing.T) {
+	filter := logger_http.PathFilter("/health", "/metrics")
+
+	assert.True(t, filter(httptest.NewRequest(http.MethodGet, "http://127.0.0.1/health", nil)))
+	assert.True(t, filter(httptest.NewRequest(http.MethodGet, "http://127.0.0.1/metrics?format=text", nil)))
+	assert.False(t, filter(httptest.NewRequest(http.MethodGet, "http://127.0.0.1/healthz", nil)))
+}
+
+func TestRateSampler(t *testing.T) {
+	request := httptest.NewRequest(http.MethodGet, "http://127.0.0.1/my-fake-url", nil)
+
+	assert.True(t, logger_http.RateSampler(1)(request))
+	assert.False(t, logger_http.RateSampler(0)(request))
+}
+
+func TestOptions_Policy(t *testing.T) {
+	o := logger_http.EvaluateServerOpt(
+		logger_http.WithRoutePolicy("/api", logger_http.WithBodyCapture(10)),
+		logger_http.WithRoutePolicy("/api/upload", logger_http.WithBodyCapture(0), logger_http.WithRedactedHeaders("X-Upload-Token")),
+		logger_http.WithRedactedHeaders("Authorization"),
+		logger_http.WithLevels(func(statusCode int) logger.Level {
+			return logger.EmergencyLevel
+		}),
+	)
+
+	defaultPolicy := o.Policy(httptest.NewRequest(http.MethodGet, "http://127.0.0.1/my-fake-url", nil))
+	assert.Same(t, o, defaultPolicy)
+	assert.Equal(t, 0, defaultPolicy.BodyCaptureLimit)
+
+	apiPolicy := o.Policy(httptest.NewRequest(http.MethodGet, "http://127.0.0.1/api/users", nil))
+	assert.Equal(t, 10, apiPolicy.BodyCaptureLimit)
+	assert.Equal(t, []string{"Authorization"}, apiPolicy.RedactedHeaders)
+	// route policies inherit the base options whatever the option order
+	assert.Equal(t, logger.EmergencyLevel, apiPolicy.LevelFunc(200))
+
+	uploadPolicy := o.Policy(httptest.NewRequest(http.MethodGet, "http://127.0.0.1/api/upload/file", nil))
+	assert.Equal(t, 0, uploadPolicy.BodyCaptureLimit)
+	assert.Equal(t, []string{"Authorization", "X-Upload-Token"}, uploadPolicy.RedactedHeaders)
+	assert.Equal(t, []string{"Authorization"}, o.RedactedHeaders)
+
+	// prefixes match on path segments
+	assert.Same(t, o, o.Policy(httptest.NewRequest(http.MethodGet, "http://127.0.0.1/apiary", nil)))
+	assert.Equal(t, 10, o.Policy(httptest.NewRequest(http.MethodGet, "http://127.0.0.1/api", nil)).BodyCaptureLimit)
+}
+
+func TestMatchPathPrefix(t *testing.T) {
+	tests := []struct {
+		path       string
+		pathPrefix string
+		expected   bool
+	}{
+		{path: "/api", pathPrefix: "/api", expected: true},
+		{path: "/api/users", pathPrefix: "/api", expected: true},
+		{path: "/apiary", pathPrefix: "/api", expected: false},
+		{path: "/ap", pathPrefix: "/api", expected: false},
+		{path: "/api/users", pathPrefix: "/api/", expected: true},
+		{path: "/anything", pathPrefix: "/", expected: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path+" "+tt.pathPrefix, func(t *testing.T) {
+			assert.Equal(t, tt.expected, logger_http.MatchPathPrefix(tt.path, tt.pathPrefix))
+		})
+	}
+}
+
+func TestWithRedactedHeaders(t *testing.T) {
+	request := httptest.NewRequest(http.MethodGet, "http://127.0.0.1/my-fake-url", nil)
+	request.Header.Set("Authorization", "Bearer my-token")
+	request.Header.Set("Accept", "application/json")
+
+	o := logger_http.EvaluateServerOpt(logger_http.WithRedactedHeaders("authorization"))
+
+	loggerContext := o.LoggerContextProvider(request)
+	header := (*loggerContext)["http_header"].Value.(http.Header)
+	assert.Equal(t, logger_http.RedactedValue, header.Get("Authorization"))
+	assert.Equal(t, "application/json", header.Get("Accept"))
+	assert.Equal(t, "Bearer my-token", request.Header.Get("Authorization"))
+}
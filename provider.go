@@ -0,0 +1,137 @@
+package logger_http
+
+import (
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/gol4ng/logger"
+)
+
+// ChainLoggerContext will merge the contexts returned by the given providers in order
+// when providers return the same key, the last provider wins
+// a provider returning nil is ignored
+func ChainLoggerContext(providers ...LoggerContextProvider) LoggerContextProvider {
+	return func(request *http.Request) *logger.Context {
+		loggerContext := logger.NewContext()
+		for _, provider := range providers {
+			if provider == nil {
+				continue
+			}
+			if ctx := provider(request); ctx != nil {
+				loggerContext.Merge(*ctx)
+			}
+		}
+		return loggerContext
+	}
+}
+
+// HeaderContext will add the request headers as http_header
+func HeaderContext(request *http.Request) *logger.Context {
+	return logger.NewContext().Add("http_header", request.Header)
+}
+
+// ClientIPContext will add the request client ip as http_client_ip
+// it uses the request RemoteAddr, see TrustedProxyClientIPContext when the service is behind proxies
+func ClientIPContext(request *http.Request) *logger.Context {
+	if ip := clientIP(request); ip != "" {
+		return logger.NewContext().Add("http_client_ip", ip)
+	}
+	return nil
+}
+
+// TrustedProxyClientIPContext will add the request client ip as http_client_ip
+// X-Forwarded-For and X-Real-Ip are only used when the request comes from one of the trusted proxies (ips or cidrs),
+// X-Forwarded-For is walked from the right and the first hop that is not a trusted proxy is the client
+// it panics when a trusted proxy is neither an ip nor a cidr
+// eg: AppendLoggerContext(TrustedProxyClientIPContext("10.0.0.0/8"))
+func TrustedProxyClientIPContext(trustedProxies ...string) LoggerContextProvider {
+	networks := make([]*net.IPNet, 0, len(trustedProxies))
+	for _, proxy := range trustedProxies {
+		if !strings.Contains(proxy, "/") {
+			if ip := net.ParseIP(proxy); ip != nil && ip.To4() != nil {
+				proxy += "/32"
+			} else {
+				proxy += "/128"
+			}
+		}
+		_, network, err := net.ParseCIDR(proxy)
+		if err != nil {
+			panic(err)
+		}
+		networks = append(networks, network)
+	}
+	trusted := func(address string) bool {
+		ip := net.ParseIP(strings.TrimSpace(address))
+		if ip == nil {
+			return false
+		}
+		for _, network := range networks {
+			if network.Contains(ip) {
+				return true
+			}
+		}
+		return false
+	}
+	return func(request *http.Request) *logger.Context {
+		ip := clientIP(request)
+		if trusted(ip) {
+			ip = forwardedClientIP(request, ip, trusted)
+		}
+		if ip != "" {
+			return logger.NewContext().Add("http_client_ip", ip)
+		}
+		return nil
+	}
+}
+
+// UserAgentContext will add the request user agent as http_user_agent
+func UserAgentContext(request *http.Request) *logger.Context {
+	if userAgent := request.UserAgent(); userAgent != "" {
+		return logger.NewContext().Add("http_user_agent", userAgent)
+	}
+	return nil
+}
+
+// HostContext will add the request host as http_host
+func HostContext(request *http.Request) *logger.Context {
+	host := request.Host
+	if host == "" && request.URL != nil {
+		host = request.URL.Host
+	}
+	if host != "" {
+		return logger.NewContext().Add("http_host", host)
+	}
+	return nil
+}
+
+func forwardedClientIP(request *http.Request, proxyIP string, trusted func(address string) bool) string {
+	var hops []string
+	for _, forwardedFor := range request.Header["X-Forwarded-For"] {
+		hops = append(hops, strings.Split(forwardedFor, ",")...)
+	}
+	for i := len(hops) - 1; i >= 0; i-- {
+		hop := strings.TrimSpace(hops[i])
+		if hop == "" {
+			continue
+		}
+		if !trusted(hop) {
+			return hop
+		}
+		proxyIP = hop
+	}
+	if len(hops) == 0 {
+		if realIP := strings.TrimSpace(request.Header.Get("X-Real-Ip")); realIP != "" {
+			return realIP
+		}
+	}
+	return proxyIP
+}
+
+// clientIP will return the ip of the request peer, forwarding headers are not trusted
+func clientIP(request *http.Request) string {
+	if host, _, err := net.SplitHostPort(request.RemoteAddr); err == nil {
+		return host
+	}
+	return request.RemoteAddr
+}
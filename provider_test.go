@@ -0,0 +1,133 @@
+package logger_http_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gol4ng/logger"
+	"github.com/stretchr/testify/assert"
+
+	logger_http "github.com/gol4ng/logger-http"
+)
+
+func TestChainLoggerContext(t *testing.T) {
+	request := httptest.NewRequest(http.MethodGet, "http://127.0.0.1/my-fake-url", nil)
+
+	provider := logger_http.ChainLoggerContext(
+		func(request *http.Request) *logger.Context {
+			return logger.NewContext().Add("first", "first_value").Add("conflict", "first_value")
+		},
+		nil,
+		func(request *http.Request) *logger.Context {
+			return nil
+		},
+		func(request *http.Request) *logger.Context {
+			return logger.NewContext().Add("conflict", "last_value")
+		},
+	)
+
+	loggerContext := provider(request)
+	assert.Len(t, *loggerContext, 2)
+	assert.Equal(t, "first_value", (*loggerContext)["first"].Value)
+	assert.Equal(t, "last_value", (*loggerContext)["conflict"].Value)
+}
+
+func TestBaseLoggerContext(t *testing.T) {
+	request := httptest.NewRequest(http.MethodGet, "http://127.0.0.1/my-fake-url", nil)
+	request.RemoteAddr = "10.0.0.1:1234"
+	request.Header.Set("User-Agent", "my-user-agent")
+
+	loggerContext := logger_http.ChainLoggerContext(
+		logger_http.HeaderContext,
+		logger_http.ClientIPContext,
+		logger_http.UserAgentContext,
+		logger_http.HostContext,
+	)(request)
+
+	assert.Equal(t, request.Header, (*loggerContext)["http_header"].Value)
+	assert.Equal(t, "10.0.0.1", (*loggerContext)["http_client_ip"].Value)
+	assert.Equal(t, "my-user-agent", (*loggerContext)["http_user_agent"].Value)
+	assert.Equal(t, "127.0.0.1", (*loggerContext)["http_host"].Value)
+}
+
+func TestClientIPContext(t *testing.T) {
+	tests := []struct {
+		name       string
+		remoteAddr string
+		header     http.Header
+		expected   interface{}
+	}{
+		{name: "remote addr", remoteAddr: "10.0.0.1:1234", header: http.Header{}, expected: "10.0.0.1"},
+		{name: "x-real-ip ignored", remoteAddr: "10.0.0.1:1234", header: http.Header{"X-Real-Ip": {"10.0.0.2"}}, expected: "10.0.0.1"},
+		{name: "x-forwarded-for ignored", remoteAddr: "10.0.0.1:1234", header: http.Header{"X-Forwarded-For": {"10.0.0.3, 10.0.0.4"}}, expected: "10.0.0.1"},
+		{name: "no ip", remoteAddr: "", header: http.Header{}, expected: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := httptest.NewRequest(http.MethodGet, "http://127.0.0.1/my-fake-url", nil)
+			request.RemoteAddr = tt.remoteAddr
+			request.Header = tt.header
+
+			loggerContext := logger_http.ClientIPContext(request)
+			if tt.expected == nil {
+				assert.Nil(t, loggerContext)
+				return
+			}
+			assert.Equal(t, tt.expected, (*loggerContext)["http_client_ip"].Value)
+		})
+	}
+}
+
+func TestTrustedProxyClientIPContext(t *testing.T) {
+	provider := logger_http.TrustedProxyClientIPContext("10.0.0.0/8", "192.168.1.1")
+	tests := []struct {
+		name       string
+		remoteAddr string
+		header     http.Header
+		expected   interface{}
+	}{
+		{name: "untrusted peer", remoteAddr: "203.0.113.1:1234", header: http.Header{"X-Forwarded-For": {"198.51.100.1"}, "X-Real-Ip": {"198.51.100.2"}}, expected: "203.0.113.1"},
+		{name: "trusted peer without header", remoteAddr: "10.0.0.1:1234", header: http.Header{}, expected: "10.0.0.1"},
+		{name: "x-real-ip", remoteAddr: "192.168.1.1:1234", header: http.Header{"X-Real-Ip": {"198.51.100.2"}}, expected: "198.51.100.2"},
+		{name: "x-forwarded-for", remoteAddr: "10.0.0.1:1234", header: http.Header{"X-Forwarded-For": {"198.51.100.1"}, "X-Real-Ip": {"198.51.100.2"}}, expected: "198.51.100.1"},
+		{name: "spoofed first hop", remoteAddr: "10.0.0.1:1234", header: http.Header{"X-Forwarded-For": {"1.2.3.4, 198.51.100.1, 10.0.0.2"}}, expected: "198.51.100.1"},
+		{name: "multiple headers", remoteAddr: "10.0.0.1:1234", header: http.Header{"X-Forwarded-For": {"1.2.3.4", "198.51.100.1"}}, expected: "198.51.100.1"},
+		{name: "only trusted hops", remoteAddr: "10.0.0.1:1234", header: http.Header{"X-Forwarded-For": {"10.0.0.3, 10.0.0.2"}}, expected: "10.0.0.3"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := httptest.NewRequest(http.MethodGet, "http://127.0.0.1/my-fake-url", nil)
+			request.RemoteAddr = tt.remoteAddr
+			request.Header = tt.header
+
+			assert.Equal(t, tt.expected, (*provider(request))["http_client_ip"].Value)
+		})
+	}
+
+	assert.Panics(t, func() { logger_http.TrustedProxyClientIPContext("not an ip") })
+}
+
+func TestAppendLoggerContext(t *testing.T) {
+	request := httptest.NewRequest(http.MethodGet, "http://127.0.0.1/my-fake-url", nil)
+
+	o := logger_http.EvaluateServerOpt(logger_http.AppendLoggerContext(func(request *http.Request) *logger.Context {
+		return logger.NewContext().Add("base_context_key", "base_context_value").Add("http_header", "overridden")
+	}))
+
+	loggerContext := o.LoggerContextProvider(request)
+	assert.Equal(t, "base_context_value", (*loggerContext)["base_context_key"].Value)
+	assert.Equal(t, "overridden", (*loggerContext)["http_header"].Value)
+}
+
+func TestPrependLoggerContext(t *testing.T) {
+	request := httptest.NewRequest(http.MethodGet, "http://127.0.0.1/my-fake-url", nil)
+
+	o := logger_http.EvaluateClientOpt(logger_http.PrependLoggerContext(func(request *http.Request) *logger.Context {
+		return logger.NewContext().Add("base_context_key", "base_context_value").Add("http_header", "overridden")
+	}))
+
+	loggerContext := o.LoggerContextProvider(request)
+	assert.Equal(t, "base_context_value", (*loggerContext)["base_context_key"].Value)
+	assert.Equal(t, request.Header, (*loggerContext)["http_header"].Value)
+}
@@ -0,0 +1,97 @@
+package logger_http
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultMaxQueueDuration is the queue duration above which the request start header is considered bogus
+const DefaultMaxQueueDuration = 5 * time.Minute
+
+// WithQueueHeaders customizes the load balancer headers carrying the request start time
+// the first present header wins, "X-Request-Start" and "X-Queue-Start" by default, no header disables the queue duration
+func WithQueueHeaders(headerNames ...string) Option {
+	return func(o *Options) {
+		o.QueueHeaders = headerNames
+	}
+}
+
+// WithMaxQueueDuration customizes the queue duration above which the request start header is ignored
+func WithMaxQueueDuration(maxDuration time.Duration) Option {
+	return func(o *Options) {
+		o.MaxQueueDuration = maxDuration
+	}
+}
+
+// QueueDuration will return the time spent between the load balancer and the startTime
+// a negative duration caused by clock skew is reported as 0, a duration above MaxQueueDuration is ignored
+func (o *Options) QueueDuration(request *http.Request, startTime time.Time) (time.Duration, bool) {
+	for _, headerName := range o.QueueHeaders {
+		value := request.Header.Get(headerName)
+		if value == "" {
+			continue
+		}
+		requestStart, ok := ParseRequestStart(value)
+		if !ok {
+			return 0, false
+		}
+		duration := startTime.Sub(requestStart)
+		if o.MaxQueueDuration > 0 && duration > o.MaxQueueDuration {
+			return 0, false
+		}
+		if duration < 0 {
+			duration = 0
+		}
+		return duration, true
+	}
+	return 0, false
+}
+
+// ParseRequestStart will parse a request start header value
+// it accepts an optional "t=" prefix and a unix timestamp in seconds (with or without fraction), milliseconds, microseconds or nanoseconds
+// eg: "t=1576252873.123", "1576252873123", "t=1576252873123456"
+func ParseRequestStart(value string) (time.Time, bool) {
+	value = strings.TrimPrefix(strings.TrimSpace(value), "t=")
+	integer, fraction := value, ""
+	if index := strings.IndexByte(value, '.'); index >= 0 {
+		integer, fraction = value[:index], value[index+1:]
+	}
+	units, err := strconv.ParseInt(integer, 10, 64)
+	if err != nil || units <= 0 {
+		return time.Time{}, false
+	}
+	// the unit is guessed from the magnitude, 1e11 seconds being far in the future
+	var scale int64
+	switch {
+	case units < 1e11:
+		scale = int64(time.Second)
+	case units < 1e14:
+		scale = int64(time.Millisecond)
+	case units < 1e17:
+		scale = int64(time.Microsecond)
+	default:
+		scale = int64(time.Nanosecond)
+	}
+	if units > math.MaxInt64/scale {
+		return time.Time{}, false
+	}
+	nanoseconds := units * scale
+	if fraction != "" {
+		digits := len(strconv.FormatInt(scale, 10)) - 1
+		if len(fraction) > digits {
+			fraction = fraction[:digits]
+		}
+		fraction += strings.Repeat("0", digits-len(fraction))
+		if digits > 0 {
+			fractionNanoseconds, err := strconv.ParseUint(fraction, 10, 64)
+			if err != nil {
+				return time.Time{}, false
+			}
+			nanoseconds += int64(fractionNanoseconds)
+		}
+	}
+	return time.Unix(0, nanoseconds), true
+}
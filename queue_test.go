@@ -0,0 +1,69 @@
+package logger_http_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	logger_http "github.com/gol4ng/logger-http"
+)
+
+func TestParseRequestStart(t *testing.T) {
+	expected := time.Date(2019, 12, 13, 16, 1, 13, 123456000, time.UTC)
+	tests := []string{
+		"1576252873.123456",
+		"t=1576252873.123456",
+		"1576252873123.456",
+		"t=1576252873123456",
+		" t=1576252873123456000 ",
+	}
+	for _, value := range tests {
+		t.Run(value, func(t *testing.T) {
+			requestStart, ok := logger_http.ParseRequestStart(value)
+			assert.True(t, ok)
+			assert.WithinDuration(t, expected, requestStart, time.Microsecond)
+		})
+	}
+
+	for _, value := range []string{"", "t=", "now", "-1576252873", "t=1e400"} {
+		t.Run(value, func(t *testing.T) {
+			_, ok := logger_http.ParseRequestStart(value)
+			assert.False(t, ok)
+		})
+	}
+}
+
+func TestOptions_QueueDuration(t *testing.T) {
+	startTime := time.Date(2019, 12, 13, 16, 1, 13, 0, time.UTC)
+	o := logger_http.EvaluateServerOpt()
+
+	request := httptest.NewRequest(http.MethodGet, "http://127.0.0.1/my-fake-url", nil)
+	_, ok := o.QueueDuration(request, startTime)
+	assert.False(t, ok)
+
+	request.Header.Set("X-Queue-Start", "t=1576252872750")
+	duration, ok := o.QueueDuration(request, startTime)
+	assert.True(t, ok)
+	assert.Equal(t, 250*time.Millisecond, duration)
+
+	request.Header.Set("X-Request-Start", "t=1576252873500")
+	duration, ok = o.QueueDuration(request, startTime)
+	assert.True(t, ok)
+	assert.Equal(t, time.Duration(0), duration, "clock skew is reported as 0")
+
+	request.Header.Set("X-Request-Start", "t=1576250000000")
+	_, ok = o.QueueDuration(request, startTime)
+	assert.False(t, ok, "duration above the max queue duration is ignored")
+
+	request.Header.Set("X-Request-Start", "invalid")
+	_, ok = o.QueueDuration(request, startTime)
+	assert.False(t, ok)
+
+	o = logger_http.EvaluateServerOpt(logger_http.WithQueueHeaders("X-Queue-Start"), logger_http.WithMaxQueueDuration(0))
+	duration, ok = o.QueueDuration(request, startTime)
+	assert.True(t, ok)
+	assert.Equal(t, 250*time.Millisecond, duration)
+}
@@ -0,0 +1,223 @@
+package logger_http
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+)
+
+// RequestRecord is the snapshot of an in-flight or ended exchange kept by the Registry
+type RequestRecord struct {
+	Id            uint64    `json:"id"`
+	Kind          string    `json:"kind"`
+	Method        string    `json:"method"`
+	URL           string    `json:"url"`
+	Route         string    `json:"route,omitempty"`
+	Peer          string    `json:"peer,omitempty"`
+	CorrelationId string    `json:"correlation_id,omitempty"`
+	StartTime     time.Time `json:"start_time"`
+	// Duration is the age of an in-flight request and the duration of an ended one, in seconds like http_duration
+	Duration     float64 `json:"duration"`
+	StatusCode   int     `json:"status_code,omitempty"`
+	BytesWritten int64   `json:"bytes_written"`
+	Error        string  `json:"error,omitempty"`
+}
+
+// Registry is an Observer keeping track of the in-flight exchanges
+// and of the last ended and failed ones, it is exposed by RequestsHandler
+type Registry struct {
+	clock     Clock
+	mutex     sync.Mutex
+	lastId    uint64
+	inFlight  map[*Exchange]uint64
+	completed *recordRing
+	failed    *recordRing
+	// onEnd is notified of every ended exchange while the server shuts down
+	onEnd func(record RequestRecord)
+}
+
+// NewRegistry will create a registry keeping the last size completed and failed exchanges
+func NewRegistry(size int, clock Clock) *Registry {
+	if clock == nil {
+		clock = RealClock
+	}
+	return &Registry{
+		clock:     clock,
+		inFlight:  map[*Exchange]uint64{},
+		completed: newRecordRing(size),
+		failed:    newRecordRing(size),
+	}
+}
+
+// WithRegistry will register every exchange in the registry
+// middleware.Logger also counts the bytes written so far when a registry is configured
+func WithRegistry(registry *Registry) Option {
+	return func(o *Options) {
+		o.Registry = registry
+		o.Observers = append(append(Observers{}, o.Observers...), registry)
+	}
+}
+
+func (r *Registry) OnStart(exchange *Exchange) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.lastId++
+	r.inFlight[exchange] = r.lastId
+}
+
+func (r *Registry) OnEnd(exchange *Exchange) {
+	errorMessage := ""
+	if exchange.Error != nil {
+		errorMessage = SanitizeString(exchange.Error.Error(), 0)
+	}
+	r.end(exchange, errorMessage, exchange.Error != nil || exchange.StatusCode >= http.StatusInternalServerError)
+}
+
+func (r *Registry) OnPanic(exchange *Exchange) {
+	r.end(exchange, "panic", true)
+}
+
+func (r *Registry) end(exchange *Exchange, errorMessage string, failed bool) {
+	r.mutex.Lock()
+	id, ok := r.inFlight[exchange]
+	if !ok {
+		r.mutex.Unlock()
+		return
+	}
+	delete(r.inFlight, exchange)
+	record := newRequestRecord(id, exchange, exchange.Duration)
+	record.StatusCode = exchange.StatusCode
+	record.Error = errorMessage
+	r.completed.push(record)
+	if failed {
+		r.failed.push(record)
+	}
+	onEnd := r.onEnd
+	r.mutex.Unlock()
+
+	if onEnd != nil {
+		onEnd(record)
+	}
+}
+
+// InFlight will return the in-flight exchanges, the oldest first
+func (r *Registry) InFlight() []RequestRecord {
+	now := r.clock.Now()
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return r.inFlightRecords(now)
+}
+
+// watch will return the in-flight exchanges and notify onEnd of the exchanges ending afterwards
+// a nil onEnd stops the notifications
+func (r *Registry) watch(onEnd func(record RequestRecord)) []RequestRecord {
+	now := r.clock.Now()
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.onEnd = onEnd
+	return r.inFlightRecords(now)
+}
+
+func (r *Registry) inFlightRecords(now time.Time) []RequestRecord {
+	records := make([]RequestRecord, 0, len(r.inFlight))
+	for exchange, id := range r.inFlight {
+		records = append(records, newRequestRecord(id, exchange, now.Sub(exchange.StartTime)))
+	}
+	sort.Slice(records, func(i, j int) bool {
+		if records[i].StartTime.Equal(records[j].StartTime) {
+			return records[i].Id < records[j].Id
+		}
+		return records[i].StartTime.Before(records[j].StartTime)
+	})
+	return records
+}
+
+// Completed will return the last ended exchanges, the most recent first
+func (r *Registry) Completed() []RequestRecord {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return r.completed.records()
+}
+
+// Failed will return the last exchanges ended with a 5xx, an error or a panic, the most recent first
+func (r *Registry) Failed() []RequestRecord {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return r.failed.records()
+}
+
+func newRequestRecord(id uint64, exchange *Exchange, duration time.Duration) RequestRecord {
+	return RequestRecord{
+		Id:            id,
+		Kind:          exchange.Kind,
+		Method:        exchange.Method,
+		URL:           exchange.URL,
+		Route:         exchange.Route,
+		Peer:          exchange.Peer,
+		CorrelationId: SanitizeString(exchange.CorrelationId, 0),
+		StartTime:     exchange.StartTime,
+		Duration:      duration.Seconds(),
+		BytesWritten:  exchange.BytesWritten(),
+	}
+}
+
+type recordRing struct {
+	buffer []RequestRecord
+	next   int
+	full   bool
+}
+
+func newRecordRing(size int) *recordRing {
+	if size < 0 {
+		size = 0
+	}
+	return &recordRing{buffer: make([]RequestRecord, size)}
+}
+
+func (r *recordRing) push(record RequestRecord) {
+	if len(r.buffer) == 0 {
+		return
+	}
+	r.buffer[r.next] = record
+	r.next = (r.next + 1) % len(r.buffer)
+	if r.next == 0 {
+		r.full = true
+	}
+}
+
+func (r *recordRing) records() []RequestRecord {
+	count := r.next
+	if r.full {
+		count = len(r.buffer)
+	}
+	records := make([]RequestRecord, 0, count)
+	for i := 1; i <= count; i++ {
+		records = append(records, r.buffer[(r.next-i+len(r.buffer))%len(r.buffer)])
+	}
+	return records
+}
+
+// RequestsHandler will expose the registry as json, like golang.org/x/net/trace /debug/requests
+// eg: adminMux.Handle("/debug/requests", logger_http.RequestsHandler(registry))
+func RequestsHandler(registry *Registry) http.Handler {
+	return http.HandlerFunc(func(writer http.ResponseWriter, req *http.Request) {
+		if req.Method != http.MethodGet {
+			writer.Header().Set("Allow", "GET")
+			http.Error(writer, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+			return
+		}
+		writer.Header().Set("Content-Type", "application/json")
+		writer.WriteHeader(http.StatusOK)
+		json.NewEncoder(writer).Encode(struct {
+			InFlight  []RequestRecord `json:"in_flight"`
+			Completed []RequestRecord `json:"completed"`
+			Failed    []RequestRecord `json:"failed"`
+		}{
+			InFlight:  registry.InFlight(),
+			Completed: registry.Completed(),
+			Failed:    registry.Failed(),
+		})
+	})
+}
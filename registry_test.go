@@ -0,0 +1,134 @@
+package logger_http_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	logger_http "github.com/gol4ng/logger-http"
+	"github.com/gol4ng/logger-http/loggerhttptest"
+)
+
+func TestWithRegistry(t *testing.T) {
+	registry := logger_http.NewRegistry(10, nil)
+	o := logger_http.EvaluateServerOpt(logger_http.WithRegistry(registry))
+	assert.Equal(t, registry, o.Registry)
+	assert.Equal(t, logger_http.Observers{registry}, o.Observers)
+}
+
+func TestRegistry(t *testing.T) {
+	startTime := time.Date(2019, 12, 13, 17, 1, 13, 0, time.UTC)
+	clock := loggerhttptest.NewClock(startTime.Add(3 * time.Second))
+	registry := logger_http.NewRegistry(10, clock)
+
+	older := &logger_http.Exchange{Kind: "server", Method: "GET", URL: "/older", CorrelationId: "my-correlation-id", StartTime: startTime}
+	newer := &logger_http.Exchange{Kind: "client", Method: "POST", URL: "/newer", Route: "/api", StartTime: startTime.Add(time.Second)}
+	registry.OnStart(newer)
+	registry.OnStart(older)
+	older.AddBytesWritten(12)
+
+	inFlight := registry.InFlight()
+	assert.Len(t, inFlight, 2)
+	assert.Equal(t, "/older", inFlight[0].URL)
+	assert.Equal(t, "my-correlation-id", inFlight[0].CorrelationId)
+	assert.Equal(t, 3.0, inFlight[0].Duration)
+	assert.Equal(t, int64(12), inFlight[0].BytesWritten)
+	assert.Equal(t, uint64(2), inFlight[0].Id)
+	assert.Equal(t, "/newer", inFlight[1].URL)
+	assert.Equal(t, "/api", inFlight[1].Route)
+	assert.Equal(t, 2.0, inFlight[1].Duration)
+
+	older.StatusCode = http.StatusOK
+	older.Duration = 250 * time.Millisecond
+	registry.OnEnd(older)
+	newer.Error = errors.New("my transport error")
+	registry.OnEnd(newer)
+	// ending an unknown exchange is ignored
+	registry.OnEnd(&logger_http.Exchange{})
+
+	assert.Empty(t, registry.InFlight())
+	completed := registry.Completed()
+	assert.Len(t, completed, 2)
+	assert.Equal(t, "/newer", completed[0].URL)
+	assert.Equal(t, "my transport error", completed[0].Error)
+	assert.Equal(t, "/older", completed[1].URL)
+	assert.Equal(t, http.StatusOK, completed[1].StatusCode)
+	assert.Equal(t, 0.25, completed[1].Duration)
+
+	failed := registry.Failed()
+	assert.Len(t, failed, 1)
+	assert.Equal(t, "/newer", failed[0].URL)
+}
+
+func TestRegistry_Failed(t *testing.T) {
+	registry := logger_http.NewRegistry(10, nil)
+
+	serverError := &logger_http.Exchange{URL: "/server-error"}
+	registry.OnStart(serverError)
+	serverError.StatusCode = http.StatusBadGateway
+	registry.OnEnd(serverError)
+
+	panicked := &logger_http.Exchange{URL: "/panic"}
+	registry.OnStart(panicked)
+	panicked.Panic = "my handler panic"
+	registry.OnPanic(panicked)
+
+	failed := registry.Failed()
+	assert.Len(t, failed, 2)
+	assert.Equal(t, "/panic", failed[0].URL)
+	assert.Equal(t, "panic", failed[0].Error)
+	assert.Equal(t, "/server-error", failed[1].URL)
+	assert.Equal(t, http.StatusBadGateway, failed[1].StatusCode)
+}
+
+func TestRegistry_RingBuffer(t *testing.T) {
+	registry := logger_http.NewRegistry(2, nil)
+	for _, url := range []string{"/first", "/second", "/third"} {
+		exchange := &logger_http.Exchange{URL: url}
+		registry.OnStart(exchange)
+		registry.OnEnd(exchange)
+	}
+
+	completed := registry.Completed()
+	assert.Len(t, completed, 2)
+	assert.Equal(t, "/third", completed[0].URL)
+	assert.Equal(t, "/second", completed[1].URL)
+
+	registry = logger_http.NewRegistry(0, nil)
+	exchange := &logger_http.Exchange{URL: "/first"}
+	registry.OnStart(exchange)
+	registry.OnEnd(exchange)
+	assert.Empty(t, registry.Completed())
+}
+
+func TestRequestsHandler(t *testing.T) {
+	registry := logger_http.NewRegistry(10, nil)
+	registry.OnStart(&logger_http.Exchange{Kind: "server", Method: "GET", URL: "/in-flight", StartTime: time.Now()})
+	handler := logger_http.RequestsHandler(registry)
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/debug/requests", nil))
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, "application/json", recorder.Header().Get("Content-Type"))
+
+	body := struct {
+		InFlight  []logger_http.RequestRecord `json:"in_flight"`
+		Completed []logger_http.RequestRecord `json:"completed"`
+		Failed    []logger_http.RequestRecord `json:"failed"`
+	}{}
+	assert.Nil(t, json.Unmarshal(recorder.Body.Bytes(), &body))
+	assert.Len(t, body.InFlight, 1)
+	assert.Equal(t, "/in-flight", body.InFlight[0].URL)
+	assert.NotNil(t, body.Completed)
+	assert.NotNil(t, body.Failed)
+
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/debug/requests", nil))
+	assert.Equal(t, http.StatusMethodNotAllowed, recorder.Code)
+	assert.Equal(t, "GET", recorder.Header().Get("Allow"))
+}
@@ -0,0 +1,129 @@
+package logger_http
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/gol4ng/logger"
+)
+
+// TruncatedMarker is appended to values exceeding the max field length
+const TruncatedMarker = "...[truncated]"
+
+// WithMaxFieldLength will cap every value derived from the request to maxLength bytes
+// truncated values are suffixed with TruncatedMarker, a maxLength lower or equal to 0 disables the cap
+func WithMaxFieldLength(maxLength int) Option {
+	return func(o *Options) {
+		o.MaxFieldLength = maxLength
+	}
+}
+
+// Sanitize will escape control characters and cap the value to the max field length
+func (o *Options) Sanitize(value string) string {
+	return SanitizeString(value, o.MaxFieldLength)
+}
+
+// SanitizeString will escape control characters (CR, LF, ANSI escapes...) so the value cannot forge log lines
+// and cap the escaped value to maxLength bytes when maxLength is greater than 0
+// the value is cut on a rune or escape sequence boundary and suffixed with TruncatedMarker
+func SanitizeString(value string, maxLength int) string {
+	index := strings.IndexFunc(value, needsEscape)
+	if index < 0 {
+		if maxLength <= 0 || len(value) <= maxLength {
+			return value
+		}
+		cut := maxLength
+		for cut > 0 && !utf8.RuneStart(value[cut]) {
+			cut--
+		}
+		return value[:cut] + TruncatedMarker
+	}
+	if maxLength > 0 && index > maxLength {
+		return SanitizeString(value[:index], maxLength)
+	}
+	builder := strings.Builder{}
+	builder.WriteString(value[:index])
+	for _, r := range value[index:] {
+		escaped := ""
+		if needsEscape(r) {
+			escaped = escapeRune(r)
+		}
+		size := len(escaped)
+		if size == 0 {
+			size = utf8.RuneLen(r)
+		}
+		if maxLength > 0 && builder.Len()+size > maxLength {
+			return builder.String() + TruncatedMarker
+		}
+		if escaped == "" {
+			builder.WriteRune(r)
+			continue
+		}
+		builder.WriteString(escaped)
+	}
+	return builder.String()
+}
+
+func escapeRune(r rune) string {
+	switch r {
+	case '\n':
+		return `\n`
+	case '\r':
+		return `\r`
+	case '\t':
+		return `\t`
+	}
+	quoted := strconv.QuoteToASCII(string(r))
+	return quoted[1 : len(quoted)-1]
+}
+
+func needsEscape(r rune) bool {
+	return r == utf8.RuneError || r == '\u2028' || r == '\u2029' || unicode.IsControl(r)
+}
+
+func sanitizeContext(provider LoggerContextProvider, maxLength int) LoggerContextProvider {
+	if provider == nil {
+		return provider
+	}
+	return func(request *http.Request) *logger.Context {
+		ctx := provider(request)
+		if ctx == nil {
+			return nil
+		}
+		sanitized := make(logger.Context, len(*ctx))
+		for _, field := range *ctx {
+			switch value := field.Value.(type) {
+			case string:
+				if field.Type == logger.StringType {
+					field.Value = SanitizeString(value, maxLength)
+				}
+			case http.Header:
+				field.Value = sanitizeHeader(value, maxLength)
+			}
+			sanitized.SetField(field)
+		}
+		return &sanitized
+	}
+}
+
+func sanitizeHeader(header http.Header, maxLength int) http.Header {
+	valueCount := 0
+	for _, values := range header {
+		valueCount += len(values)
+	}
+	// every header shares one backing array, capped so an append cannot overwrite the next header
+	allValues := make([]string, valueCount)
+	sanitized := make(http.Header, len(header))
+	for name, values := range header {
+		sanitizedValues := allValues[:len(values):len(values)]
+		allValues = allValues[len(values):]
+		for i, value := range values {
+			sanitizedValues[i] = SanitizeString(value, maxLength)
+		}
+		sanitized[SanitizeString(name, maxLength)] = sanitizedValues
+	}
+	return sanitized
+}
@@ -0,0 +1,60 @@
+package logger_http_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gol4ng/logger"
+	"github.com/stretchr/testify/assert"
+
+	logger_http "github.com/gol4ng/logger-http"
+)
+
+func TestSanitizeString(t *testing.T) {
+	tests := []struct {
+		name      string
+		value     string
+		maxLength int
+		expected  string
+	}{
+		{name: "clean value", value: "/my-fake-url", expected: "/my-fake-url"},
+		{name: "crlf", value: "/my-fake-url\r\n<info> forged entry", expected: `/my-fake-url\r\n<info> forged entry`},
+		{name: "ansi escape", value: "\x1b[31mred\x1b[0m", expected: `\x1b[31mred\x1b[0m`},
+		{name: "unicode line separator", value: "a\u2028b", expected: `a\u2028b`},
+		{name: "invalid utf8", value: "a\xffb", expected: `a\ufffdb`},
+		{name: "truncated", value: "0123456789", maxLength: 4, expected: "0123" + logger_http.TruncatedMarker},
+		{name: "truncated on rune boundary", value: "aéé", maxLength: 2, expected: "a" + logger_http.TruncatedMarker},
+		{name: "not truncated", value: "0123", maxLength: 4, expected: "0123"},
+		{name: "escaped value capped", value: "\x1b\x1b\x1b\x1b", maxLength: 10, expected: `\x1b\x1b` + logger_http.TruncatedMarker},
+		{name: "escape sequence kept whole", value: "ab\r\n", maxLength: 5, expected: `ab\r` + logger_http.TruncatedMarker},
+		{name: "escaped value fits", value: "a\nb", maxLength: 4, expected: `a\nb`},
+		{name: "clean prefix longer than cap", value: "0123456789\n", maxLength: 4, expected: "0123" + logger_http.TruncatedMarker},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, logger_http.SanitizeString(tt.value, tt.maxLength))
+		})
+	}
+}
+
+func TestSanitizeLoggerContext(t *testing.T) {
+	request := httptest.NewRequest(http.MethodGet, "http://127.0.0.1/my-fake-url", nil)
+	request.Header.Set("X-Forged", "value\r\n<info> forged entry")
+	request.Header.Set("X-Long", "0123456789")
+
+	o := logger_http.EvaluateServerOpt(
+		logger_http.WithMaxFieldLength(8),
+		logger_http.AppendLoggerContext(func(request *http.Request) *logger.Context {
+			return logger.NewContext().Add("custom", "custom\nvalue").Add("number", 12)
+		}),
+	)
+
+	loggerContext := o.LoggerContextProvider(request)
+	header := (*loggerContext)["http_header"].Value.(http.Header)
+	assert.Equal(t, `value\r`+logger_http.TruncatedMarker, header.Get("X-Forged"))
+	assert.Equal(t, "01234567"+logger_http.TruncatedMarker, header.Get("X-Long"))
+	assert.Equal(t, `custom\n`+logger_http.TruncatedMarker, (*loggerContext)["custom"].Value)
+	assert.Equal(t, int64(12), (*loggerContext)["number"].Value)
+	assert.Equal(t, "0123456789", request.Header.Get("X-Long"))
+}
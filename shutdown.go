@@ -0,0 +1,110 @@
+package logger_http
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gol4ng/logger"
+)
+
+// Shutdown will gracefully shut the server down and report its in-flight requests with log
+// the registry must be given to the middleware.Logger of the server with WithRegistry
+// it logs the in-flight requests when the shutdown starts, every request completing during the drain,
+// and the requests still running when ctx is done, the server is then closed to abandon them
+// eg: logger_http.Shutdown(ctx, server, registry, myLogger)
+func Shutdown(ctx context.Context, server *http.Server, registry *Registry, log logger.LoggerInterface) error {
+	mutex := sync.Mutex{}
+	draining := map[uint64]bool{}
+	drained := 0
+	done := false
+
+	// the draining set is filled before onEnd can run: it waits for the mutex,
+	// it is called outside the registry lock so holding the mutex around watch cannot deadlock
+	mutex.Lock()
+	inFlight := registry.watch(func(record RequestRecord) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		if done || !draining[record.Id] {
+			return
+		}
+		delete(draining, record.Id)
+		drained++
+		log.Info("http server request drained "+recordMessage(record), recordFields(record)...)
+	})
+	defer registry.watch(nil)
+	descriptions := make([]string, 0, len(inFlight))
+	for _, record := range inFlight {
+		if record.Kind != "server" {
+			continue
+		}
+		draining[record.Id] = true
+		descriptions = append(descriptions, recordMessage(record))
+	}
+	mutex.Unlock()
+	log.Notice(
+		"http server shutdown started with "+strconv.Itoa(len(descriptions))+" in-flight requests",
+		logger.Any("http_in_flight_count", len(descriptions)),
+		logger.Any("http_in_flight", descriptions),
+	)
+
+	err := server.Shutdown(ctx)
+	abandoned := 0
+	if err != nil {
+		// the drain deadline is reached, closing the server abandons the remaining requests
+		mutex.Lock()
+		for _, record := range registry.InFlight() {
+			if !draining[record.Id] {
+				continue
+			}
+			delete(draining, record.Id)
+			abandoned++
+			log.Warning("http server request abandoned "+recordMessage(record), recordFields(record)...)
+		}
+		mutex.Unlock()
+		server.Close()
+	}
+
+	// the counters are snapshotted, the requests ending after the summary are not reported
+	mutex.Lock()
+	done = true
+	drainedCount := drained
+	mutex.Unlock()
+	log.Notice(
+		"http server shutdown done, "+strconv.Itoa(drainedCount)+" drained and "+strconv.Itoa(abandoned)+" abandoned requests",
+		logger.Any("http_drained_count", drainedCount),
+		logger.Any("http_abandoned_count", abandoned),
+	)
+	return err
+}
+
+func recordMessage(record RequestRecord) string {
+	message := record.Method + " " + record.URL + " [duration:" + time.Duration(record.Duration*float64(time.Second)).String()
+	if record.StatusCode != 0 {
+		message += ", status_code:" + strconv.Itoa(record.StatusCode)
+	}
+	return message + "]"
+}
+
+func recordFields(record RequestRecord) []logger.Field {
+	fields := []logger.Field{
+		logger.String("http_method", record.Method),
+		logger.String("http_url", record.URL),
+		logger.String("http_kind", record.Kind),
+		logger.String("http_start_time", record.StartTime.Format(time.RFC3339)),
+		logger.Float64("http_duration", record.Duration),
+		logger.Any("http_response_length", record.BytesWritten),
+	}
+	if record.StatusCode != 0 {
+		fields = append(fields, logger.Any("http_status_code", record.StatusCode))
+	}
+	if record.CorrelationId != "" {
+		fields = append(fields, logger.String("http_correlation_id", record.CorrelationId))
+	}
+	if record.Error != "" {
+		fields = append(fields, logger.String("http_error_message", record.Error))
+	}
+	return fields
+}
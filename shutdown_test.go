@@ -0,0 +1,140 @@
+package logger_http_test
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gol4ng/logger"
+	testing_logger "github.com/gol4ng/logger/testing"
+	"github.com/stretchr/testify/assert"
+
+	logger_http "github.com/gol4ng/logger-http"
+)
+
+func TestShutdown(t *testing.T) {
+	registry := logger_http.NewRegistry(10, nil)
+	o := logger_http.EvaluateServerOpt(logger_http.WithRegistry(registry))
+	releaseDrained := make(chan struct{})
+	releaseHung := make(chan struct{})
+	defer close(releaseHung)
+
+	server := &http.Server{Handler: http.HandlerFunc(func(writer http.ResponseWriter, req *http.Request) {
+		exchange := o.NewExchange("server", req, time.Now())
+		o.Observers.OnStart(exchange)
+		defer func() {
+			exchange.StatusCode = http.StatusOK
+			exchange.Duration = time.Since(exchange.StartTime)
+			o.Observers.OnEnd(exchange)
+		}()
+		if req.URL.Path == "/drained" {
+			<-releaseDrained
+			return
+		}
+		<-releaseHung
+	})}
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.Nil(t, err)
+	go server.Serve(listener)
+
+	requests := sync.WaitGroup{}
+	for _, path := range []string{"/drained", "/hung"} {
+		requests.Add(1)
+		go func(path string) {
+			defer requests.Done()
+			if resp, err := http.Get("http://" + listener.Addr().String() + path); err == nil {
+				resp.Body.Close()
+			}
+		}(path)
+	}
+	assert.Eventually(t, func() bool { return len(registry.InFlight()) == 2 }, time.Second, time.Millisecond)
+
+	myLogger, store := testing_logger.NewLogger()
+	ctx, cancel := context.WithTimeout(context.Background(), 300*time.Millisecond)
+	defer cancel()
+	go func() {
+		assert.Eventually(t, func() bool { return len(store.GetEntries()) > 0 }, time.Second, time.Millisecond)
+		close(releaseDrained)
+	}()
+
+	err = logger_http.Shutdown(ctx, server, registry, myLogger)
+	assert.Equal(t, context.DeadlineExceeded, err)
+	requests.Wait()
+
+	entries := store.GetEntries()
+	assert.Len(t, entries, 4)
+
+	assert.Equal(t, logger.NoticeLevel, entries[0].Level)
+	assert.Equal(t, "http server shutdown started with 2 in-flight requests", entries[0].Message)
+	assert.EqualValues(t, 2, (*entries[0].Context)["http_in_flight_count"].Value)
+	assert.Len(t, (*entries[0].Context)["http_in_flight"].Value, 2)
+
+	assert.Equal(t, logger.InfoLevel, entries[1].Level)
+	assert.Contains(t, entries[1].Message, "http server request drained GET /drained [duration:")
+	assert.Contains(t, entries[1].Message, "status_code:200]")
+	assert.EqualValues(t, http.StatusOK, (*entries[1].Context)["http_status_code"].Value)
+
+	assert.Equal(t, logger.WarningLevel, entries[2].Level)
+	assert.Contains(t, entries[2].Message, "http server request abandoned GET /hung [duration:")
+
+	assert.Equal(t, "http server shutdown done, 1 drained and 1 abandoned requests", entries[3].Message)
+	assert.EqualValues(t, 1, (*entries[3].Context)["http_drained_count"].Value)
+	assert.EqualValues(t, 1, (*entries[3].Context)["http_abandoned_count"].Value)
+}
+
+func TestShutdown_RequestsEndingAtStart(t *testing.T) {
+	const count = 20
+	registry := logger_http.NewRegistry(count, nil)
+	o := logger_http.EvaluateServerOpt(logger_http.WithRegistry(registry))
+	release := make(chan struct{})
+
+	server := &http.Server{Handler: http.HandlerFunc(func(writer http.ResponseWriter, req *http.Request) {
+		exchange := o.NewExchange("server", req, time.Now())
+		o.Observers.OnStart(exchange)
+		defer o.Observers.OnEnd(exchange)
+		<-release
+	})}
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.Nil(t, err)
+	go server.Serve(listener)
+
+	requests := sync.WaitGroup{}
+	for i := 0; i < count; i++ {
+		requests.Add(1)
+		go func() {
+			defer requests.Done()
+			if resp, err := http.Get("http://" + listener.Addr().String()); err == nil {
+				resp.Body.Close()
+			}
+		}()
+	}
+	assert.Eventually(t, func() bool { return len(registry.InFlight()) == count }, time.Second, time.Millisecond)
+
+	// the requests end while the shutdown starts watching the registry
+	myLogger, store := testing_logger.NewLogger()
+	close(release)
+	assert.Nil(t, logger_http.Shutdown(context.Background(), server, registry, myLogger))
+	requests.Wait()
+
+	entries := store.GetEntries()
+	inFlight := (*entries[0].Context)["http_in_flight_count"].Value
+	summary := entries[len(entries)-1]
+	assert.EqualValues(t, inFlight, (*summary.Context)["http_drained_count"].Value)
+	assert.EqualValues(t, 0, (*summary.Context)["http_abandoned_count"].Value)
+	assert.Len(t, entries, int(inFlight.(int64))+2)
+}
+
+func TestShutdown_NoInFlight(t *testing.T) {
+	registry := logger_http.NewRegistry(10, nil)
+	myLogger, store := testing_logger.NewLogger()
+
+	assert.Nil(t, logger_http.Shutdown(context.Background(), &http.Server{}, registry, myLogger))
+
+	entries := store.GetEntries()
+	assert.Len(t, entries, 2)
+	assert.Equal(t, "http server shutdown started with 0 in-flight requests", entries[0].Message)
+	assert.Equal(t, "http server shutdown done, 0 drained and 0 abandoned requests", entries[1].Message)
+}
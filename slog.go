@@ -0,0 +1,142 @@
+//go:build go1.21
+// +build go1.21
+
+package logger_http
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"sort"
+
+	"github.com/gol4ng/logger"
+)
+
+type slogContextKey struct{}
+
+// InjectSlogInContext will inject a *slog.Logger into the go-context
+func InjectSlogInContext(ctx context.Context, l *slog.Logger) context.Context {
+	return context.WithValue(ctx, slogContextKey{}, l)
+}
+
+// SlogFromContext will retrieve a *slog.Logger from the go-context or return defaultLogger
+func SlogFromContext(ctx context.Context, defaultLogger *slog.Logger) *slog.Logger {
+	if l, ok := ctx.Value(slogContextKey{}).(*slog.Logger); ok && l != nil {
+		return l
+	}
+	return defaultLogger
+}
+
+// SlogLevel will convert a gol4ng logger.Level into a slog.Level
+// levels without slog equivalent are placed between the standard ones
+func SlogLevel(level logger.Level) slog.Level {
+	switch level {
+	case logger.DebugLevel:
+		return slog.LevelDebug
+	case logger.InfoLevel:
+		return slog.LevelInfo
+	case logger.NoticeLevel:
+		return slog.LevelInfo + 2
+	case logger.WarningLevel:
+		return slog.LevelWarn
+	case logger.ErrorLevel:
+		return slog.LevelError
+	case logger.CriticalLevel:
+		return slog.LevelError + 4
+	case logger.AlertLevel:
+		return slog.LevelError + 8
+	}
+	return slog.LevelError + 12
+}
+
+// SlogAttrs will convert gol4ng fields into slog attributes sorted by key
+// http.Header values are converted into a group of header attributes
+func SlogAttrs(fields ...logger.Field) []slog.Attr {
+	attrs := make([]slog.Attr, 0, len(fields))
+	for _, field := range fields {
+		if field.Type == logger.SkipType {
+			continue
+		}
+		if header, ok := field.Value.(http.Header); ok {
+			attrs = append(attrs, slogHeaderGroup(field.Name, header))
+			continue
+		}
+		attrs = append(attrs, slog.Any(field.Name, field.Value))
+	}
+	sort.Slice(attrs, func(i, j int) bool {
+		return attrs[i].Key < attrs[j].Key
+	})
+	return attrs
+}
+
+func slogHeaderGroup(name string, header http.Header) slog.Attr {
+	if len(header) == 0 {
+		// slog handlers drop empty groups
+		return slog.Any(name, header)
+	}
+	headerNames := make([]string, 0, len(header))
+	for headerName := range header {
+		headerNames = append(headerNames, headerName)
+	}
+	sort.Strings(headerNames)
+	attrs := make([]interface{}, 0, len(headerNames))
+	for _, headerName := range headerNames {
+		attrs = append(attrs, slog.Any(headerName, header[headerName]))
+	}
+	return slog.Group(name, attrs...)
+}
+
+// NewSlogBridge will create a gol4ng logger.LoggerInterface writing into the given *slog.Logger
+// so gol4ng and slog based services produce identical field sets
+func NewSlogBridge(ctx context.Context, l *slog.Logger) logger.LoggerInterface {
+	return &slogBridge{ctx: ctx, logger: l}
+}
+
+type slogBridge struct {
+	ctx    context.Context
+	logger *slog.Logger
+}
+
+func (b *slogBridge) Log(message string, level logger.Level, fields ...logger.Field) {
+	slogLevel := SlogLevel(level)
+	if !b.logger.Enabled(b.ctx, slogLevel) {
+		return
+	}
+	b.logger.LogAttrs(b.ctx, slogLevel, message, SlogAttrs(fields...)...)
+}
+
+func (b *slogBridge) Enabled(level logger.Level) bool {
+	return b.logger.Enabled(b.ctx, SlogLevel(level))
+}
+
+func (b *slogBridge) Debug(message string, fields ...logger.Field) {
+	b.Log(message, logger.DebugLevel, fields...)
+}
+
+func (b *slogBridge) Info(message string, fields ...logger.Field) {
+	b.Log(message, logger.InfoLevel, fields...)
+}
+
+func (b *slogBridge) Notice(message string, fields ...logger.Field) {
+	b.Log(message, logger.NoticeLevel, fields...)
+}
+
+func (b *slogBridge) Warning(message string, fields ...logger.Field) {
+	b.Log(message, logger.WarningLevel, fields...)
+}
+
+func (b *slogBridge) Error(message string, fields ...logger.Field) {
+	b.Log(message, logger.ErrorLevel, fields...)
+}
+
+func (b *slogBridge) Critical(message string, fields ...logger.Field) {
+	b.Log(message, logger.CriticalLevel, fields...)
+}
+
+func (b *slogBridge) Alert(message string, fields ...logger.Field) {
+	b.Log(message, logger.AlertLevel, fields...)
+}
+
+func (b *slogBridge) Emergency(message string, fields ...logger.Field) {
+	b.Log(message, logger.EmergencyLevel, fields...)
+}
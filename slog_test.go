@@ -0,0 +1,71 @@
+//go:build go1.21
+// +build go1.21
+
+package logger_http_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"testing"
+
+	"github.com/gol4ng/logger"
+	"github.com/stretchr/testify/assert"
+
+	logger_http "github.com/gol4ng/logger-http"
+)
+
+func TestSlogFromContext(t *testing.T) {
+	myLogger := slog.New(slog.NewJSONHandler(&bytes.Buffer{}, nil))
+	defaultLogger := slog.New(slog.NewJSONHandler(&bytes.Buffer{}, nil))
+
+	assert.Same(t, defaultLogger, logger_http.SlogFromContext(context.Background(), defaultLogger))
+	assert.Nil(t, logger_http.SlogFromContext(context.Background(), nil))
+
+	ctx := logger_http.InjectSlogInContext(context.Background(), myLogger)
+	assert.Same(t, myLogger, logger_http.SlogFromContext(ctx, defaultLogger))
+}
+
+func TestSlogLevel(t *testing.T) {
+	assert.Equal(t, slog.LevelDebug, logger_http.SlogLevel(logger.DebugLevel))
+	assert.Equal(t, slog.LevelInfo, logger_http.SlogLevel(logger.InfoLevel))
+	assert.Equal(t, slog.LevelWarn, logger_http.SlogLevel(logger.WarningLevel))
+	assert.Equal(t, slog.LevelError, logger_http.SlogLevel(logger.ErrorLevel))
+
+	levels := []logger.Level{
+		logger.DebugLevel, logger.InfoLevel, logger.NoticeLevel, logger.WarningLevel,
+		logger.ErrorLevel, logger.CriticalLevel, logger.AlertLevel, logger.EmergencyLevel,
+	}
+	for i := 1; i < len(levels); i++ {
+		assert.True(t, logger_http.SlogLevel(levels[i-1]) < logger_http.SlogLevel(levels[i]))
+	}
+}
+
+func TestNewSlogBridge(t *testing.T) {
+	buffer := &bytes.Buffer{}
+	bridge := logger_http.NewSlogBridge(context.Background(), slog.New(slog.NewJSONHandler(buffer, &slog.HandlerOptions{
+		Level: slog.LevelInfo,
+	})))
+
+	bridge.Debug("filtered debug")
+	bridge.Warning("my warning",
+		logger.String("http_method", "GET"),
+		logger.Int64("http_status_code", 200),
+		logger.Any("http_header", http.Header{"Accept": {"application/json"}}),
+		logger.Error("http_error", errors.New("my error")),
+		logger.Skip("skipped", "value"),
+	)
+
+	entry := map[string]interface{}{}
+	assert.Nil(t, json.Unmarshal(buffer.Bytes(), &entry))
+	assert.Equal(t, "WARN", entry["level"])
+	assert.Equal(t, "my warning", entry["msg"])
+	assert.Equal(t, "GET", entry["http_method"])
+	assert.Equal(t, float64(200), entry["http_status_code"])
+	assert.Equal(t, map[string]interface{}{"Accept": []interface{}{"application/json"}}, entry["http_header"])
+	assert.Equal(t, "my error", entry["http_error"])
+	assert.NotContains(t, entry, "skipped")
+}
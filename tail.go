@@ -0,0 +1,210 @@
+package logger_http
+
+import (
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gol4ng/logger"
+)
+
+// TimestampFieldName is the field holding the time a buffered entry was logged
+// it is the field name used by logger/middleware.Timestamp
+const TimestampFieldName = "timestamp"
+
+// WithTailBuffer will hold the debug and info entries logged during a request in memory (up to limit entries)
+// they are flushed in order when the request ends with a 5xx, a panic or lasts at least slowDuration, and discarded otherwise
+// a limit lower or equal to 0 disables the buffering, a slowDuration of 0 disables the slow trigger
+// the buffered entries carry the time they were logged at in the timestamp field, the stock logger/middleware.Timestamp
+// overwrites it with the flush time: use KeepTimestamp in its place
+func WithTailBuffer(limit int, slowDuration time.Duration) Option {
+	return func(o *Options) {
+		o.TailBufferLimit = limit
+		o.TailSlowDuration = slowDuration
+	}
+}
+
+// ShouldFlushTail will return true when the buffered entries of a request ending with statusCode after duration must be flushed
+func (o *Options) ShouldFlushTail(statusCode int, duration time.Duration) bool {
+	return statusCode >= http.StatusInternalServerError || (o.TailSlowDuration > 0 && duration >= o.TailSlowDuration)
+}
+
+type tailState int
+
+const (
+	tailBuffering tailState = iota
+	tailFlushed
+	tailDiscarded
+)
+
+type tailEntry struct {
+	logger  logger.LoggerInterface
+	message string
+	level   logger.Level
+	fields  []logger.Field
+}
+
+// tailBuffer is shared by a TailLogger and every logger wrapped from it
+// once limit entries are held, entries is used as a ring whose oldest entry is at start
+type tailBuffer struct {
+	mutex   sync.Mutex
+	limit   int
+	entries []tailEntry
+	start   int
+	dropped int
+	state   tailState
+}
+
+func (b *tailBuffer) push(entry tailEntry) {
+	if b.limit <= 0 || len(b.entries) < b.limit {
+		b.entries = append(b.entries, entry)
+		return
+	}
+	b.entries[b.start] = entry
+	b.start = (b.start + 1) % b.limit
+	b.dropped++
+}
+
+// take will return the entries in order and empty the buffer
+func (b *tailBuffer) take() ([]tailEntry, int) {
+	entries := append(b.entries[b.start:], b.entries[:b.start]...)
+	dropped := b.dropped
+	b.entries, b.start, b.dropped = nil, 0, 0
+	return entries, dropped
+}
+
+// TailLogger is a request scoped logger holding the debug and info entries until the request ends
+// entries with a level of notice or above are logged immediately
+type TailLogger struct {
+	buffer *tailBuffer
+	logger logger.LoggerInterface
+	clock  Clock
+}
+
+// NewTailLogger will create a TailLogger buffering up to limit entries for the given logger
+// when the limit is reached the oldest entries are dropped
+func NewTailLogger(l logger.LoggerInterface, clock Clock, limit int) *TailLogger {
+	if clock == nil {
+		clock = RealClock
+	}
+	return &TailLogger{buffer: &tailBuffer{limit: limit}, logger: l, clock: clock}
+}
+
+func (l *TailLogger) Log(message string, level logger.Level, fields ...logger.Field) {
+	if level <= logger.NoticeLevel {
+		l.logger.Log(message, level, fields...)
+		return
+	}
+	l.buffer.mutex.Lock()
+	switch l.buffer.state {
+	case tailFlushed:
+		// entries logged once the request failed are not held anymore
+		l.buffer.mutex.Unlock()
+		l.logger.Log(message, level, fields...)
+		return
+	case tailDiscarded:
+		l.buffer.mutex.Unlock()
+		return
+	}
+	defer l.buffer.mutex.Unlock()
+	bufferedFields := make([]logger.Field, 0, len(fields)+1)
+	bufferedFields = append(append(bufferedFields, fields...), logger.Time(TimestampFieldName, l.clock.Now()))
+	l.buffer.push(tailEntry{logger: l.logger, message: message, level: level, fields: bufferedFields})
+}
+
+// Flush will log the buffered entries in order, entries logged afterwards are not buffered anymore
+func (l *TailLogger) Flush() {
+	l.buffer.mutex.Lock()
+	entries, dropped := l.buffer.take()
+	l.buffer.state = tailFlushed
+	l.buffer.mutex.Unlock()
+
+	if dropped > 0 {
+		l.logger.Info("logger-http tail buffer dropped "+strconv.Itoa(dropped)+" entries", logger.Any("http_tail_dropped", dropped))
+	}
+	for _, entry := range entries {
+		entry.logger.Log(entry.message, entry.level, entry.fields...)
+	}
+}
+
+// Discard will forget the buffered entries, debug and info entries logged afterwards are discarded too
+func (l *TailLogger) Discard() {
+	l.buffer.mutex.Lock()
+	defer l.buffer.mutex.Unlock()
+	l.buffer.take()
+	l.buffer.state = tailDiscarded
+}
+
+// Len will return the number of buffered entries
+func (l *TailLogger) Len() int {
+	l.buffer.mutex.Lock()
+	defer l.buffer.mutex.Unlock()
+	return len(l.buffer.entries)
+}
+
+// Wrap will wrap the underlying logger when it is a logger.WrappableLoggerInterface
+func (l *TailLogger) Wrap(middlewares ...logger.MiddlewareInterface) logger.LoggerInterface {
+	if wrappableLogger, ok := l.logger.(logger.WrappableLoggerInterface); ok {
+		wrappableLogger.Wrap(middlewares...)
+	}
+	return l
+}
+
+// WrapNew will return a TailLogger sharing the same buffer with a wrapped underlying logger
+// the middlewares are applied when the entries are flushed
+func (l *TailLogger) WrapNew(middlewares ...logger.MiddlewareInterface) logger.LoggerInterface {
+	wrapped := l.logger
+	if wrappableLogger, ok := l.logger.(logger.WrappableLoggerInterface); ok {
+		wrapped = wrappableLogger.WrapNew(middlewares...)
+	}
+	return &TailLogger{buffer: l.buffer, logger: wrapped, clock: l.clock}
+}
+
+func (l *TailLogger) Debug(message string, fields ...logger.Field) {
+	l.Log(message, logger.DebugLevel, fields...)
+}
+
+func (l *TailLogger) Info(message string, fields ...logger.Field) {
+	l.Log(message, logger.InfoLevel, fields...)
+}
+
+func (l *TailLogger) Notice(message string, fields ...logger.Field) {
+	l.Log(message, logger.NoticeLevel, fields...)
+}
+
+func (l *TailLogger) Warning(message string, fields ...logger.Field) {
+	l.Log(message, logger.WarningLevel, fields...)
+}
+
+func (l *TailLogger) Error(message string, fields ...logger.Field) {
+	l.Log(message, logger.ErrorLevel, fields...)
+}
+
+func (l *TailLogger) Critical(message string, fields ...logger.Field) {
+	l.Log(message, logger.CriticalLevel, fields...)
+}
+
+func (l *TailLogger) Alert(message string, fields ...logger.Field) {
+	l.Log(message, logger.AlertLevel, fields...)
+}
+
+func (l *TailLogger) Emergency(message string, fields ...logger.Field) {
+	l.Log(message, logger.EmergencyLevel, fields...)
+}
+
+// KeepTimestamp is a gol4ng logger middleware adding the timestamp field like logger/middleware.Timestamp
+// except for entries already holding one, so flushed entries keep the time they were logged at
+func KeepTimestamp() logger.MiddlewareInterface {
+	return func(handler logger.HandlerInterface) logger.HandlerInterface {
+		return func(entry logger.Entry) error {
+			if entry.Context == nil {
+				entry.Context = &logger.Context{}
+			}
+			if _, ok := (*entry.Context)[TimestampFieldName]; !ok {
+				entry.Context.SetField(logger.Time(TimestampFieldName, time.Now()))
+			}
+			return handler(entry)
+		}
+	}
+}
@@ -0,0 +1,160 @@
+package logger_http_test
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gol4ng/logger"
+	"github.com/gol4ng/logger/middleware"
+	testing_logger "github.com/gol4ng/logger/testing"
+	"github.com/stretchr/testify/assert"
+
+	logger_http "github.com/gol4ng/logger-http"
+	"github.com/gol4ng/logger-http/loggerhttptest"
+)
+
+func TestWithTailBuffer(t *testing.T) {
+	o := logger_http.EvaluateServerOpt()
+	assert.Equal(t, 0, o.TailBufferLimit)
+
+	o = logger_http.EvaluateServerOpt(logger_http.WithTailBuffer(100, time.Second))
+	assert.Equal(t, 100, o.TailBufferLimit)
+	assert.Equal(t, time.Second, o.TailSlowDuration)
+	assert.Equal(t, 0, o.DebugPolicy().TailBufferLimit)
+}
+
+func TestOptions_ShouldFlushTail(t *testing.T) {
+	o := logger_http.EvaluateServerOpt(logger_http.WithTailBuffer(100, time.Second))
+	assert.False(t, o.ShouldFlushTail(http.StatusOK, 10*time.Millisecond))
+	assert.False(t, o.ShouldFlushTail(http.StatusNotFound, 10*time.Millisecond))
+	assert.True(t, o.ShouldFlushTail(http.StatusBadGateway, 10*time.Millisecond))
+	assert.True(t, o.ShouldFlushTail(http.StatusOK, time.Second))
+
+	o = logger_http.EvaluateServerOpt(logger_http.WithTailBuffer(100, 0))
+	assert.False(t, o.ShouldFlushTail(http.StatusOK, time.Hour))
+}
+
+func TestTailLogger_Flush(t *testing.T) {
+	clock := loggerhttptest.NewClock(time.Date(2019, 12, 13, 17, 1, 13, 0, time.UTC))
+	myLogger, store := testing_logger.NewLogger()
+	tailLogger := logger_http.NewTailLogger(myLogger, clock, 10)
+
+	tailLogger.Debug("first", logger.String("my_field", "my_value"))
+	clock.Add(time.Second)
+	tailLogger.Info("second")
+	tailLogger.Warning("warning")
+
+	entries := store.GetEntries()
+	assert.Len(t, entries, 1)
+	assert.Equal(t, "warning", entries[0].Message)
+	assert.Equal(t, 2, tailLogger.Len())
+
+	store.CleanEntries()
+	tailLogger.Flush()
+	entries = store.GetEntries()
+	assert.Len(t, entries, 2)
+	assert.Equal(t, "first", entries[0].Message)
+	assert.Equal(t, logger.DebugLevel, entries[0].Level)
+	assert.Equal(t, "my_value", (*entries[0].Context)["my_field"].Value)
+	assert.Equal(t, time.Date(2019, 12, 13, 17, 1, 13, 0, time.UTC), (*entries[0].Context)[logger_http.TimestampFieldName].Value)
+	assert.Equal(t, "second", entries[1].Message)
+	assert.Equal(t, time.Date(2019, 12, 13, 17, 1, 14, 0, time.UTC), (*entries[1].Context)[logger_http.TimestampFieldName].Value)
+
+	// entries logged once flushed are not held anymore
+	store.CleanEntries()
+	tailLogger.Debug("late")
+	assert.Len(t, store.GetEntries(), 1)
+	assert.Equal(t, 0, tailLogger.Len())
+}
+
+func TestTailLogger_Discard(t *testing.T) {
+	myLogger, store := testing_logger.NewLogger()
+	tailLogger := logger_http.NewTailLogger(myLogger, nil, 10)
+
+	tailLogger.Debug("first")
+	tailLogger.Discard()
+	tailLogger.Info("late")
+	tailLogger.Error("error")
+	tailLogger.Flush()
+
+	entries := store.GetEntries()
+	assert.Len(t, entries, 1)
+	assert.Equal(t, "error", entries[0].Message)
+}
+
+func TestTailLogger_Limit(t *testing.T) {
+	myLogger, store := testing_logger.NewLogger()
+	tailLogger := logger_http.NewTailLogger(myLogger, nil, 2)
+
+	tailLogger.Debug("first")
+	tailLogger.Debug("second")
+	tailLogger.Debug("third")
+	tailLogger.Debug("fourth")
+	tailLogger.Debug("fifth")
+	assert.Equal(t, 2, tailLogger.Len())
+	tailLogger.Flush()
+
+	entries := store.GetEntries()
+	assert.Len(t, entries, 3)
+	assert.Equal(t, "logger-http tail buffer dropped 3 entries", entries[0].Message)
+	assert.EqualValues(t, 3, (*entries[0].Context)["http_tail_dropped"].Value)
+	assert.Equal(t, "fourth", entries[1].Message)
+	assert.Equal(t, "fifth", entries[2].Message)
+}
+
+func TestTailLogger_WrapNew(t *testing.T) {
+	myLogger, store := testing_logger.NewLogger()
+	tailLogger := logger_http.NewTailLogger(myLogger, nil, 10)
+	wrapped := tailLogger.WrapNew(middleware.Context(logger.NewContext().Add("correlation_id", "my-correlation-id")))
+
+	tailLogger.Debug("first")
+	wrapped.Debug("second")
+	assert.Equal(t, 2, tailLogger.Len())
+	tailLogger.Flush()
+
+	entries := store.GetEntries()
+	assert.Len(t, entries, 2)
+	assert.NotContains(t, *entries[0].Context, "correlation_id")
+	assert.Equal(t, "my-correlation-id", (*entries[1].Context)["correlation_id"].Value)
+}
+
+func TestKeepTimestamp(t *testing.T) {
+	myLogger, store := testing_logger.NewLogger()
+	wrapped := myLogger.WrapNew(logger_http.KeepTimestamp())
+	timestamp := time.Date(2019, 12, 13, 17, 1, 13, 0, time.UTC)
+
+	wrapped.Info("kept", logger.Time(logger_http.TimestampFieldName, timestamp))
+	wrapped.Info("added")
+
+	entries := store.GetEntries()
+	assert.Len(t, entries, 2)
+	assert.Equal(t, timestamp, (*entries[0].Context)[logger_http.TimestampFieldName].Value)
+	assert.IsType(t, time.Time{}, (*entries[1].Context)[logger_http.TimestampFieldName].Value)
+	assert.NotEqual(t, timestamp, (*entries[1].Context)[logger_http.TimestampFieldName].Value)
+}
+
+func TestTailLogger_Timestamp(t *testing.T) {
+	loggedAt := time.Date(2019, 12, 13, 17, 1, 13, 0, time.UTC)
+	tests := []struct {
+		name              string
+		timestamp         logger.MiddlewareInterface
+		expectedTimestamp bool
+	}{
+		{name: "stock Timestamp overwrites the logged time", timestamp: middleware.Timestamp(), expectedTimestamp: false},
+		{name: "KeepTimestamp keeps the logged time", timestamp: logger_http.KeepTimestamp(), expectedTimestamp: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			myLogger, store := testing_logger.NewLogger()
+			tailLogger := logger_http.NewTailLogger(myLogger.WrapNew(tt.timestamp), loggerhttptest.NewClock(loggedAt), 10)
+
+			tailLogger.Debug("buffered")
+			tailLogger.Flush()
+
+			entries := store.GetEntries()
+			assert.Len(t, entries, 1)
+			assert.Equal(t, tt.expectedTimestamp, loggedAt.Equal((*entries[0].Context)[logger_http.TimestampFieldName].Value.(time.Time)))
+		})
+	}
+}
@@ -0,0 +1,137 @@
+package tripperware
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	"github.com/gol4ng/httpware/v4"
+	"github.com/gol4ng/logger"
+
+	logger_http "github.com/gol4ng/logger-http"
+	"github.com/gol4ng/logger-http/cassette"
+)
+
+// Cassette will record the exchanges into the cassette or replay them from it depending on the cassette mode
+// interactions are keyed by method, sanitized url and body hash, urls and headers are stored redacted
+// in replay mode a request without recorded interaction fails with a *cassette.MissError
+// describing the difference with the closest recorded request, the miss is logged as a warning with the context logger or log
+// eg:
+//
+//	mode := cassette.ModeReplay
+//	if os.Getenv("RECORD") != "" {
+//		mode = cassette.ModeRecord
+//	}
+//	stack := httpware.TripperwareStack(
+//		tripperware.Logger(myLogger),
+//		tripperware.Cassette(myLogger, cassette.New("testdata/cassettes", mode), logger_http.WithRedactedHeaders("Authorization")),
+//	)
+func Cassette(log logger.LoggerInterface, c *cassette.Cassette, opts ...logger_http.Option) httpware.Tripperware {
+	o := logger_http.EvaluateClientOpt(opts...)
+	return func(next http.RoundTripper) http.RoundTripper {
+		return httpware.RoundTripFunc(func(req *http.Request) (*http.Response, error) {
+			policy := o.Policy(req)
+			if policy.RequestFilter != nil && policy.RequestFilter(req) {
+				return next.RoundTrip(req)
+			}
+
+			req, body, err := readRequestBody(req)
+			if err != nil {
+				return nil, err
+			}
+			request := cassette.Request{
+				Method: req.Method,
+				URL:    policy.SanitizeURL(req.URL),
+				Header: logger_http.RedactHeader(req.Header, policy.RedactedHeaders...),
+				Body:   body,
+			}
+			key := cassette.Key(request.Method, request.URL, body)
+
+			if c.Mode() == cassette.ModeRecord {
+				return recordInteraction(log, c, policy, next, req, cassette.Interaction{Key: key, Request: request})
+			}
+
+			interaction, err := c.Load(key)
+			if err == cassette.ErrNotRecorded {
+				missError := c.Miss(request)
+				if currentLogger := logger.FromContext(req.Context(), log); currentLogger != nil {
+					currentLogger.Warning(policy.Sanitize(missError.Error()), logger.String("cassette_key", key), logger.String("cassette_diff", policy.Sanitize(strings.Join(missError.Diff, ", "))))
+				}
+				return nil, missError
+			}
+			if err != nil {
+				return nil, err
+			}
+			return &http.Response{
+				Status:        interaction.Response.Status,
+				StatusCode:    interaction.Response.StatusCode,
+				Proto:         "HTTP/1.1",
+				ProtoMajor:    1,
+				ProtoMinor:    1,
+				Header:        interaction.Response.Header.Clone(),
+				Body:          ioutil.NopCloser(bytes.NewReader(interaction.Response.Body)),
+				ContentLength: int64(len(interaction.Response.Body)),
+				Request:       req,
+			}, nil
+		})
+	}
+}
+
+func recordInteraction(log logger.LoggerInterface, c *cassette.Cassette, policy *logger_http.Options, next http.RoundTripper, req *http.Request, interaction cassette.Interaction) (*http.Response, error) {
+	resp, err := next.RoundTrip(req)
+	if err != nil {
+		return resp, err
+	}
+	body, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	resp.Body = ioutil.NopCloser(bytes.NewReader(body))
+	if err != nil {
+		return resp, err
+	}
+	interaction.Response = cassette.Response{
+		StatusCode: resp.StatusCode,
+		Status:     resp.Status,
+		Header:     logger_http.RedactHeader(resp.Header, policy.RedactedHeaders...),
+		Body:       body,
+	}
+	if saveErr := c.Save(interaction); saveErr != nil {
+		if currentLogger := logger.FromContext(req.Context(), log); currentLogger != nil {
+			currentLogger.Warning("cassette interaction not recorded", logger.Error("cassette_error", saveErr))
+		}
+	}
+	return resp, nil
+}
+
+// readRequestBody will read the whole request body and close it as a RoundTripper must
+// the caller's request is left untouched, the returned shallow copy carries a replayable body
+func readRequestBody(req *http.Request) (*http.Request, []byte, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return req, nil, nil
+	}
+	defer req.Body.Close()
+
+	var body []byte
+	var err error
+	if req.GetBody != nil {
+		var getBody io.ReadCloser
+		if getBody, err = req.GetBody(); err != nil {
+			return nil, nil, err
+		}
+		body, err = ioutil.ReadAll(getBody)
+		getBody.Close()
+	} else {
+		body, err = ioutil.ReadAll(req.Body)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	clone := *req
+	clone.Body = ioutil.NopCloser(bytes.NewReader(body))
+	clone.GetBody = func() (io.ReadCloser, error) {
+		return ioutil.NopCloser(bytes.NewReader(body)), nil
+	}
+	return &clone, body, nil
+}
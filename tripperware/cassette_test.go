@@ -0,0 +1,156 @@
+package tripperware_test
+
+import (
+	"errors"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/gol4ng/logger"
+	testing_logger "github.com/gol4ng/logger/testing"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/gol4ng/logger-http"
+	"github.com/gol4ng/logger-http/cassette"
+	"github.com/gol4ng/logger-http/loggerhttptest"
+	"github.com/gol4ng/logger-http/tripperware"
+)
+
+func TestCassette(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cassette")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		body, _ := ioutil.ReadAll(req.Body)
+		assert.Equal(t, "my request body", string(body))
+		rw.Header().Set("Set-Cookie", "session=secret")
+		rw.WriteHeader(http.StatusCreated)
+		rw.Write([]byte(`my response body`))
+	}))
+
+	opts := []logger_http.Option{
+		logger_http.WithRedactedHeaders("Authorization", "Set-Cookie"),
+		logger_http.WithRedactedQueryParams("token"),
+	}
+	newRequest := func(token string) *http.Request {
+		request, _ := http.NewRequest(http.MethodPost, server.URL+"/my-fake-url?token="+token, strings.NewReader("my request body"))
+		request.Header.Set("Authorization", "Bearer "+token)
+		return request
+	}
+
+	recorder := tripperware.Cassette(logger.NewNopLogger(), cassette.New(dir, cassette.ModeRecord), opts...)(http.DefaultTransport)
+	response, err := recorder.RoundTrip(newRequest("first"))
+	assert.Nil(t, err)
+	body, _ := ioutil.ReadAll(response.Body)
+	assert.Equal(t, "my response body", string(body))
+	server.Close()
+
+	interactions, err := cassette.New(dir, cassette.ModeReplay).Interactions()
+	assert.Nil(t, err)
+	assert.Len(t, interactions, 1)
+	assert.Equal(t, server.URL+"/my-fake-url?token=[REDACTED]", interactions[0].Request.URL)
+	assert.Equal(t, logger_http.RedactedValue, interactions[0].Request.Header.Get("Authorization"))
+	assert.Equal(t, logger_http.RedactedValue, interactions[0].Response.Header.Get("Set-Cookie"))
+
+	player := tripperware.Cassette(logger.NewNopLogger(), cassette.New(dir, cassette.ModeReplay), opts...)(loggerhttptest.ErrorTransport(errors.New("network access")))
+	response, err = player.RoundTrip(newRequest("second"))
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusCreated, response.StatusCode)
+	assert.Equal(t, "201 Created", response.Status)
+	body, _ = ioutil.ReadAll(response.Body)
+	assert.Equal(t, "my response body", string(body))
+}
+
+type closeRecorder struct {
+	*strings.Reader
+	closed bool
+}
+
+func (c *closeRecorder) Close() error {
+	c.closed = true
+	return nil
+}
+
+func TestCassette_RequestBody(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cassette")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	c := cassette.New(dir, cassette.ModeReplay)
+	assert.Nil(t, c.Save(cassette.Interaction{
+		Key:      cassette.Key(http.MethodPost, "http://127.0.0.1/my-fake-url", []byte("my request body")),
+		Request:  cassette.Request{Method: http.MethodPost, URL: "http://127.0.0.1/my-fake-url"},
+		Response: cassette.Response{StatusCode: http.StatusOK, Status: "200 OK"},
+	}))
+	transport := tripperware.Cassette(logger.NewNopLogger(), c)(loggerhttptest.ErrorTransport(errors.New("network access")))
+
+	tests := []struct {
+		name    string
+		getBody bool
+	}{
+		{name: "without GetBody"},
+		{name: "with GetBody", getBody: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body := &closeRecorder{Reader: strings.NewReader("my request body")}
+			request := httptest.NewRequest(http.MethodPost, "http://127.0.0.1/my-fake-url", nil)
+			request.Body = body
+			if tt.getBody {
+				request.GetBody = func() (io.ReadCloser, error) {
+					return ioutil.NopCloser(strings.NewReader("my request body")), nil
+				}
+			}
+
+			response, err := transport.RoundTrip(request)
+			assert.Nil(t, err)
+			assert.Equal(t, http.StatusOK, response.StatusCode)
+			assert.True(t, body.closed)
+			assert.Equal(t, body, request.Body)
+			assert.Equal(t, tt.getBody, request.GetBody != nil)
+		})
+	}
+}
+
+func TestCassette_Miss(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cassette")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	c := cassette.New(dir, cassette.ModeReplay)
+	assert.Nil(t, c.Save(cassette.Interaction{
+		Key:      cassette.Key(http.MethodGet, "http://127.0.0.1/my-fake-url?page=1", nil),
+		Request:  cassette.Request{Method: http.MethodGet, URL: "http://127.0.0.1/my-fake-url?page=1"},
+		Response: cassette.Response{StatusCode: http.StatusOK, Status: "200 OK"},
+	}))
+
+	myLogger, store := testing_logger.NewLogger()
+	transport := tripperware.Cassette(myLogger, c)(loggerhttptest.ErrorTransport(errors.New("network access")))
+
+	_, err = transport.RoundTrip(httptest.NewRequest(http.MethodGet, "http://127.0.0.1/my-fake-url?page=2", nil))
+	assert.True(t, errors.Is(err, cassette.ErrNotRecorded))
+	assert.EqualError(t, err, `cassette: no interaction recorded for GET http://127.0.0.1/my-fake-url?page=2, closest GET http://127.0.0.1/my-fake-url?page=1 differs by query page "1" != "2"`)
+
+	entries := store.GetEntries()
+	assert.Len(t, entries, 1)
+	assert.Equal(t, logger.WarningLevel, entries[0].Level)
+	assert.Equal(t, err.Error(), entries[0].Message)
+	assert.Equal(t, `query page "1" != "2"`, (*entries[0].Context)["cassette_diff"].Value)
+
+	store.CleanEntries()
+	request := httptest.NewRequest(http.MethodGet, "http://127.0.0.1/my-fake-url?page=1", nil)
+	request.Method = "GET\nlevel=error msg=forged"
+	_, err = transport.RoundTrip(request)
+	assert.True(t, errors.Is(err, cassette.ErrNotRecorded))
+
+	entries = store.GetEntries()
+	assert.Len(t, entries, 1)
+	assert.NotContains(t, entries[0].Message, "\n")
+	assert.Contains(t, entries[0].Message, `GET\nlevel=error msg=forged`)
+	assert.NotContains(t, (*entries[0].Context)["cassette_diff"].Value, "\n")
+}
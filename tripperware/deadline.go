@@ -0,0 +1,52 @@
+package tripperware
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/gol4ng/httpware/v4"
+	"github.com/gol4ng/logger"
+
+	"github.com/gol4ng/logger-http"
+)
+
+// Deadline will propagate the remaining budget of the request context to the downstream service with the budget header
+// a call with an already exhausted budget is not sent: it is logged as a warning and fails with the context error
+// eg:
+//
+//	stack := httpware.TripperwareStack(
+//		tripperware.Logger(l),
+//		tripperware.Deadline(l),
+//	)
+func Deadline(log logger.LoggerInterface, opts ...logger_http.Option) httpware.Tripperware {
+	o := logger_http.EvaluateClientOpt(opts...)
+	return func(next http.RoundTripper) http.RoundTripper {
+		return httpware.RoundTripFunc(func(req *http.Request) (*http.Response, error) {
+			ctx := req.Context()
+			remaining, ok := logger_http.RemainingBudget(ctx, o.Clock.Now())
+			if !ok {
+				return next.RoundTrip(req)
+			}
+			if remaining <= 0 {
+				if currentLogger := logger.FromContext(ctx, log); currentLogger != nil {
+					currentLogger.Warning(
+						fmt.Sprintf("http client call not sent with an exhausted budget %s %s", o.Sanitize(req.Method), o.Sanitize(o.SanitizeURL(req.URL))),
+						logger.Float64("http_remaining_budget", remaining.Seconds()),
+					)
+				}
+				// a RoundTripper must close the request body, even on error
+				if req.Body != nil {
+					req.Body.Close()
+				}
+				if err := ctx.Err(); err != nil {
+					return nil, err
+				}
+				return nil, context.DeadlineExceeded
+			}
+			req = req.Clone(ctx)
+			req.Header.Set(o.BudgetHeader, logger_http.FormatBudget(remaining))
+			return next.RoundTrip(req)
+		})
+	}
+}
@@ -0,0 +1,96 @@
+package tripperware_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gol4ng/httpware/v4"
+	"github.com/gol4ng/logger"
+	testing_logger "github.com/gol4ng/logger/testing"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/gol4ng/logger-http"
+	"github.com/gol4ng/logger-http/loggerhttptest"
+	"github.com/gol4ng/logger-http/middleware"
+	"github.com/gol4ng/logger-http/tripperware"
+)
+
+func TestDeadline(t *testing.T) {
+	clock := loggerhttptest.NewClock(time.Date(2019, 12, 13, 17, 1, 13, 0, time.UTC))
+	var budget string
+	transport := httpware.RoundTripFunc(func(req *http.Request) (*http.Response, error) {
+		budget = req.Header.Get(logger_http.DefaultBudgetHeader)
+		return &http.Response{StatusCode: http.StatusOK}, nil
+	})
+
+	myLogger, store := testing_logger.NewLogger()
+	decoratedTransport := tripperware.Deadline(myLogger, logger_http.WithClock(clock))(transport)
+
+	request := httptest.NewRequest(http.MethodGet, "http://127.0.0.1/my-fake-url", nil)
+	_, err := decoratedTransport.RoundTrip(request)
+	assert.Nil(t, err)
+	assert.Equal(t, "", budget)
+
+	ctx, cancel := context.WithDeadline(request.Context(), clock.Now().Add(1500*time.Millisecond))
+	defer cancel()
+	_, err = decoratedTransport.RoundTrip(request.WithContext(ctx))
+	assert.Nil(t, err)
+	assert.Equal(t, "1500", budget)
+	assert.Equal(t, "", request.Header.Get(logger_http.DefaultBudgetHeader))
+	assert.Len(t, store.GetEntries(), 0)
+
+	clock.Add(1500*time.Millisecond - time.Microsecond)
+	_, err = decoratedTransport.RoundTrip(request.WithContext(ctx))
+	assert.Nil(t, err)
+	assert.Equal(t, "1", budget)
+
+	budget = ""
+	clock.Add(time.Second)
+	_, err = decoratedTransport.RoundTrip(request.WithContext(ctx))
+	assert.Equal(t, context.DeadlineExceeded, err)
+	assert.Equal(t, "", budget)
+
+	entries := store.GetEntries()
+	assert.Len(t, entries, 1)
+	assert.Equal(t, logger.WarningLevel, entries[0].Level)
+	assert.Equal(t, "http client call not sent with an exhausted budget GET http://127.0.0.1/my-fake-url", entries[0].Message)
+	assert.Equal(t, -0.999999, (*entries[0].Context)["http_remaining_budget"].Value)
+}
+
+func TestDeadline_RoundTrip(t *testing.T) {
+	clock := loggerhttptest.NewClock(time.Date(2019, 12, 13, 17, 1, 13, 0, time.UTC))
+	var deadline time.Time
+	var hasDeadline bool
+	handler := middleware.Deadline(logger_http.WithClock(clock))(http.HandlerFunc(func(writer http.ResponseWriter, innerRequest *http.Request) {
+		deadline, hasDeadline = innerRequest.Context().Deadline()
+	}))
+	transport := tripperware.Deadline(logger.NewNopLogger(), logger_http.WithClock(clock))(httpware.RoundTripFunc(func(req *http.Request) (*http.Response, error) {
+		recorder := httptest.NewRecorder()
+		// the downstream service only knows the budget header
+		handler.ServeHTTP(recorder, req.WithContext(context.Background()))
+		return recorder.Result(), nil
+	}))
+
+	tests := []struct {
+		name             string
+		budget           time.Duration
+		expectedDeadline time.Duration
+	}{
+		{name: "budget", budget: 1500 * time.Millisecond, expectedDeadline: 1500 * time.Millisecond},
+		{name: "sub millisecond budget", budget: 300 * time.Microsecond, expectedDeadline: time.Millisecond},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hasDeadline = false
+			ctx, cancel := context.WithDeadline(context.Background(), clock.Now().Add(tt.budget))
+			defer cancel()
+			_, err := transport.RoundTrip(httptest.NewRequest(http.MethodGet, "http://127.0.0.1/my-fake-url", nil).WithContext(ctx))
+			assert.Nil(t, err)
+			assert.True(t, hasDeadline)
+			assert.Equal(t, clock.Now().Add(tt.expectedDeadline), deadline)
+		})
+	}
+}
@@ -0,0 +1,292 @@
+package tripperware
+
+import (
+	"crypto/tls"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptrace"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gol4ng/httpware/v4"
+	"github.com/gol4ng/logger"
+
+	logger_http "github.com/gol4ng/logger-http"
+	"github.com/gol4ng/logger-http/har"
+)
+
+// HAR will record every request/response exchange into the sink using the HTTP Archive 1.2 format
+// it uses the same options as Logger: urls and headers are redacted with the configured rules
+// and bodies are only recorded up to the WithBodyCapture limit
+// the entry is added to the sink when the response body reaches EOF or is closed, so timings.receive covers the body read,
+// a response body that is never closed is never recorded, sink errors are logged as warnings with the context logger or log
+// eg:
+//
+//	buffer := har.NewBuffer(100)
+//	stack := httpware.TripperwareStack(
+//		tripperware.HAR(myLogger, buffer, logger_http.WithRedactedHeaders("Authorization"), logger_http.WithBodyCapture(4096)),
+//	)
+//	...
+//	buffer.Dump(os.Stdout)
+func HAR(log logger.LoggerInterface, sink har.Sink, opts ...logger_http.Option) httpware.Tripperware {
+	o := logger_http.EvaluateClientOpt(opts...)
+	return func(next http.RoundTripper) http.RoundTripper {
+		return httpware.RoundTripFunc(func(req *http.Request) (*http.Response, error) {
+			policy := o.Policy(req)
+			if policy.RequestFilter != nil && policy.RequestFilter(req) {
+				return next.RoundTrip(req)
+			}
+			if policy.Sampler != nil && !policy.Sampler(req) {
+				return next.RoundTrip(req)
+			}
+
+			trace := &harTrace{clock: policy.Clock, start: policy.Clock.Now()}
+			entry := har.Entry{
+				StartedDateTime: trace.start.Format(time.RFC3339Nano),
+				Request:         harRequest(policy, req),
+			}
+
+			resp, err := next.RoundTrip(req.WithContext(httptrace.WithClientTrace(req.Context(), trace.clientTrace())))
+			end := policy.Clock.Now()
+
+			if resp != nil {
+				entry.Response = harResponse(policy, resp)
+			} else {
+				entry.Response = har.Response{Cookies: []har.Cookie{}, Headers: []har.NameValue{}, HeadersSize: -1, BodySize: -1}
+			}
+			if err != nil {
+				entry.Response.Error = err.Error()
+			}
+			entry.ServerIPAddress = trace.serverIPAddress()
+
+			record := func(end time.Time) {
+				entry.Time = milliseconds(end.Sub(trace.start))
+				entry.Timings = trace.timings(end)
+				if addErr := sink.Add(entry); addErr != nil {
+					if currentLogger := logger.FromContext(req.Context(), log); currentLogger != nil {
+						currentLogger.Warning("har entry not recorded", logger.Error("har_error", addErr))
+					}
+				}
+			}
+			// upgraded connections keep their io.ReadWriteCloser body
+			if err != nil || resp == nil || resp.Body == nil || resp.Body == http.NoBody || resp.StatusCode == http.StatusSwitchingProtocols {
+				record(end)
+				return resp, err
+			}
+			resp.Body = &harBody{ReadCloser: resp.Body, clock: policy.Clock, record: record}
+			return resp, err
+		})
+	}
+}
+
+func harRequest(policy *logger_http.Options, req *http.Request) har.Request {
+	request := har.Request{
+		Method:      req.Method,
+		URL:         policy.SanitizeURL(req.URL),
+		HTTPVersion: httpVersion(req.Proto),
+		Cookies:     []har.Cookie{},
+		Headers:     harHeaders(logger_http.RedactHeader(req.Header, policy.RedactedHeaders...)),
+		QueryString: []har.NameValue{},
+		HeadersSize: -1,
+		BodySize:    req.ContentLength,
+	}
+	for name, values := range req.URL.Query() {
+		for _, value := range values {
+			if policy.IsRedactedQueryParam(name) {
+				value = logger_http.RedactedValue
+			}
+			request.QueryString = append(request.QueryString, har.NameValue{Name: name, Value: value})
+		}
+	}
+	sort.SliceStable(request.QueryString, func(i, j int) bool {
+		return request.QueryString[i].Name < request.QueryString[j].Name
+	})
+	if body := logger_http.GetBodyPrefix(req.GetBody, policy.BodyCaptureLimit); body != nil {
+		request.PostData = &har.PostData{
+			MimeType: req.Header.Get("Content-Type"),
+			Text:     string(body),
+			Comment:  truncatedComment(int64(len(body)), req.ContentLength, policy.BodyCaptureLimit),
+		}
+	}
+	return request
+}
+
+func harResponse(policy *logger_http.Options, resp *http.Response) har.Response {
+	response := har.Response{
+		Status:      resp.StatusCode,
+		StatusText:  strings.TrimPrefix(resp.Status, strconv.Itoa(resp.StatusCode)+" "),
+		HTTPVersion: httpVersion(resp.Proto),
+		Cookies:     []har.Cookie{},
+		Headers:     harHeaders(logger_http.RedactHeader(resp.Header, policy.RedactedHeaders...)),
+		Content: har.Content{
+			Size:     resp.ContentLength,
+			MimeType: resp.Header.Get("Content-Type"),
+		},
+		RedirectURL: resp.Header.Get("Location"),
+		HeadersSize: -1,
+		BodySize:    resp.ContentLength,
+	}
+	if policy.BodyCaptureLimit > 0 && resp.Body != nil {
+		var body []byte
+		body, resp.Body = logger_http.PeekBody(resp.Body, policy.BodyCaptureLimit)
+		response.Content.Text = string(body)
+		response.Content.Comment = truncatedComment(int64(len(body)), resp.ContentLength, policy.BodyCaptureLimit)
+	}
+	return response
+}
+
+func harHeaders(header http.Header) []har.NameValue {
+	names := make([]string, 0, len(header))
+	for name := range header {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	headers := []har.NameValue{}
+	for _, name := range names {
+		for _, value := range header[name] {
+			headers = append(headers, har.NameValue{Name: name, Value: value})
+		}
+	}
+	return headers
+}
+
+func httpVersion(proto string) string {
+	if proto == "" {
+		return "HTTP/1.1"
+	}
+	return proto
+}
+
+func truncatedComment(captured int64, size int64, limit int) string {
+	if captured == int64(limit) && size != captured {
+		return "truncated to " + strconv.Itoa(limit) + " bytes"
+	}
+	return ""
+}
+
+func milliseconds(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
+
+// harBody records the har entry the first time the response body reaches EOF or is closed
+type harBody struct {
+	io.ReadCloser
+	clock  logger_http.Clock
+	once   sync.Once
+	record func(end time.Time)
+}
+
+func (b *harBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if err == io.EOF {
+		b.done()
+	}
+	return n, err
+}
+
+func (b *harBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.done()
+	return err
+}
+
+func (b *harBody) done() {
+	b.once.Do(func() {
+		b.record(b.clock.Now())
+	})
+}
+
+// harTrace collects the httptrace events of a round trip
+type harTrace struct {
+	mu    sync.Mutex
+	clock logger_http.Clock
+	start time.Time
+
+	dnsStart, dnsDone         time.Time
+	connectStart, connectDone time.Time
+	tlsStart, tlsDone         time.Time
+	gotConn, wroteRequest     time.Time
+	firstByte                 time.Time
+	remoteAddr                net.Addr
+}
+
+func (t *harTrace) mark(target *time.Time) {
+	now := t.clock.Now()
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if target.IsZero() {
+		*target = now
+	}
+}
+
+func (t *harTrace) clientTrace() *httptrace.ClientTrace {
+	return &httptrace.ClientTrace{
+		DNSStart:          func(httptrace.DNSStartInfo) { t.mark(&t.dnsStart) },
+		DNSDone:           func(httptrace.DNSDoneInfo) { t.mark(&t.dnsDone) },
+		ConnectStart:      func(string, string) { t.mark(&t.connectStart) },
+		ConnectDone:       func(string, string, error) { t.mark(&t.connectDone) },
+		TLSHandshakeStart: func() { t.mark(&t.tlsStart) },
+		TLSHandshakeDone:  func(tls.ConnectionState, error) { t.mark(&t.tlsDone) },
+		GotConn: func(info httptrace.GotConnInfo) {
+			t.mark(&t.gotConn)
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			if info.Conn != nil {
+				t.remoteAddr = info.Conn.RemoteAddr()
+			}
+		},
+		WroteRequest:         func(httptrace.WroteRequestInfo) { t.mark(&t.wroteRequest) },
+		GotFirstResponseByte: func() { t.mark(&t.firstByte) },
+	}
+}
+
+func (t *harTrace) timings(end time.Time) har.Timings {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	timings := har.Timings{Blocked: -1, DNS: -1, Connect: -1, SSL: -1}
+	if t.gotConn.IsZero() || t.wroteRequest.IsZero() || t.firstByte.IsZero() {
+		// the transport does not support httptrace
+		timings.Wait = milliseconds(end.Sub(t.start))
+		return timings
+	}
+	blocked := t.gotConn.Sub(t.start)
+	if !t.dnsStart.IsZero() && !t.dnsDone.IsZero() {
+		timings.DNS = milliseconds(t.dnsDone.Sub(t.dnsStart))
+		blocked -= t.dnsDone.Sub(t.dnsStart)
+	}
+	if !t.connectStart.IsZero() && !t.connectDone.IsZero() {
+		connectEnd := t.connectDone
+		if t.tlsDone.After(connectEnd) {
+			connectEnd = t.tlsDone
+		}
+		timings.Connect = milliseconds(connectEnd.Sub(t.connectStart))
+		blocked -= connectEnd.Sub(t.connectStart)
+	}
+	if !t.tlsStart.IsZero() && !t.tlsDone.IsZero() {
+		timings.SSL = milliseconds(t.tlsDone.Sub(t.tlsStart))
+	}
+	if blocked > 0 {
+		timings.Blocked = milliseconds(blocked)
+	}
+	timings.Send = milliseconds(t.wroteRequest.Sub(t.gotConn))
+	timings.Wait = milliseconds(t.firstByte.Sub(t.wroteRequest))
+	timings.Receive = milliseconds(end.Sub(t.firstByte))
+	return timings
+}
+
+func (t *harTrace) serverIPAddress() string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.remoteAddr == nil {
+		return ""
+	}
+	if host, _, err := net.SplitHostPort(t.remoteAddr.String()); err == nil {
+		return host
+	}
+	return t.remoteAddr.String()
+}
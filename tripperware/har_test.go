@@ -0,0 +1,177 @@
+package tripperware_test
+
+import (
+	"errors"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/http/httptrace"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gol4ng/httpware/v4"
+	"github.com/gol4ng/logger"
+	testing_logger "github.com/gol4ng/logger/testing"
+	"github.com/stretchr/testify/assert"
+
+	logger_http "github.com/gol4ng/logger-http"
+	"github.com/gol4ng/logger-http/har"
+	"github.com/gol4ng/logger-http/loggerhttptest"
+	"github.com/gol4ng/logger-http/tripperware"
+)
+
+func TestHAR(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.Header().Set("Content-Type", "text/plain")
+		rw.Header().Set("Set-Cookie", "session=secret")
+		rw.Write([]byte(`my response body`))
+	}))
+	defer server.Close()
+
+	buffer := har.NewBuffer(0)
+	c := http.Client{
+		Transport: tripperware.HAR(logger.NewNopLogger(), buffer,
+			logger_http.WithRedactedHeaders("Authorization", "Set-Cookie"),
+			logger_http.WithRedactedQueryParams("token"),
+			logger_http.WithBodyCapture(7),
+		)(http.DefaultTransport),
+	}
+
+	request, _ := http.NewRequest(http.MethodPost, server.URL+"/my-fake-url?token=secret&page=1", strings.NewReader("my request body"))
+	request.Header.Set("Authorization", "Bearer secret")
+	request.Header.Set("Content-Type", "text/plain")
+	response, err := c.Do(request)
+	assert.Nil(t, err)
+	body, _ := ioutil.ReadAll(response.Body)
+	assert.Equal(t, "my response body", string(body))
+
+	entries := buffer.Entries()
+	assert.Len(t, entries, 1)
+	entry := entries[0]
+
+	assert.Equal(t, http.MethodPost, entry.Request.Method)
+	assert.Equal(t, server.URL+"/my-fake-url?token=[REDACTED]&page=1", entry.Request.URL)
+	assert.Equal(t, "HTTP/1.1", entry.Request.HTTPVersion)
+	assert.Contains(t, entry.Request.Headers, har.NameValue{Name: "Authorization", Value: logger_http.RedactedValue})
+	assert.Equal(t, []har.NameValue{{Name: "page", Value: "1"}, {Name: "token", Value: logger_http.RedactedValue}}, entry.Request.QueryString)
+	assert.Equal(t, &har.PostData{MimeType: "text/plain", Text: "my requ", Comment: "truncated to 7 bytes"}, entry.Request.PostData)
+	assert.Equal(t, int64(15), entry.Request.BodySize)
+
+	assert.Equal(t, http.StatusOK, entry.Response.Status)
+	assert.Equal(t, "OK", entry.Response.StatusText)
+	assert.Contains(t, entry.Response.Headers, har.NameValue{Name: "Set-Cookie", Value: logger_http.RedactedValue})
+	assert.Equal(t, har.Content{Size: 16, MimeType: "text/plain", Text: "my resp", Comment: "truncated to 7 bytes"}, entry.Response.Content)
+
+	assert.Equal(t, "127.0.0.1", entry.ServerIPAddress)
+	assert.True(t, entry.Time >= 0)
+	assert.True(t, entry.Timings.Send >= 0)
+	assert.True(t, entry.Timings.Wait >= 0)
+	assert.True(t, entry.Timings.Receive >= 0)
+	assert.Equal(t, float64(-1), entry.Timings.SSL)
+}
+
+type slowBody struct {
+	io.Reader
+	clock *loggerhttptest.Clock
+}
+
+func (b *slowBody) Read(p []byte) (int, error) {
+	b.clock.Add(time.Second)
+	return b.Reader.Read(p)
+}
+
+func (b *slowBody) Close() error {
+	return nil
+}
+
+func TestHAR_ReceiveTiming(t *testing.T) {
+	clock := loggerhttptest.NewClock(time.Date(2020, time.January, 1, 0, 0, 0, 0, time.UTC))
+	transport := httpware.RoundTripFunc(func(req *http.Request) (*http.Response, error) {
+		trace := httptrace.ContextClientTrace(req.Context())
+		trace.GotConn(httptrace.GotConnInfo{})
+		clock.Add(time.Millisecond)
+		trace.WroteRequest(httptrace.WroteRequestInfo{})
+		clock.Add(2 * time.Millisecond)
+		trace.GotFirstResponseByte()
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     http.Header{},
+			Body:       &slowBody{Reader: strings.NewReader("my response body"), clock: clock},
+		}, nil
+	})
+
+	tests := []struct {
+		name    string
+		consume func(body io.ReadCloser)
+	}{
+		{name: "read until EOF", consume: func(body io.ReadCloser) { ioutil.ReadAll(body) }},
+		{name: "closed before EOF", consume: func(body io.ReadCloser) {
+			body.Read(make([]byte, 2))
+			body.Close()
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buffer := har.NewBuffer(0)
+			response, err := tripperware.HAR(logger.NewNopLogger(), buffer, logger_http.WithClock(clock))(transport).RoundTrip(httptest.NewRequest(http.MethodGet, "http://127.0.0.1/my-fake-url", nil))
+			assert.Nil(t, err)
+			assert.Len(t, buffer.Entries(), 0)
+
+			tt.consume(response.Body)
+			response.Body.Close()
+
+			entries := buffer.Entries()
+			assert.Len(t, entries, 1)
+			assert.Equal(t, float64(1), entries[0].Timings.Send)
+			assert.Equal(t, float64(2), entries[0].Timings.Wait)
+			assert.True(t, entries[0].Timings.Receive >= 1000)
+			assert.Equal(t, entries[0].Timings.Send+entries[0].Timings.Wait+entries[0].Timings.Receive, entries[0].Time)
+		})
+	}
+}
+
+func TestHAR_WithError(t *testing.T) {
+	buffer := har.NewBuffer(0)
+	transport := tripperware.HAR(logger.NewNopLogger(), buffer)(loggerhttptest.ErrorTransport(errors.New("my transport error")))
+
+	_, err := transport.RoundTrip(httptest.NewRequest(http.MethodGet, "http://127.0.0.1/my-fake-url", nil))
+	assert.EqualError(t, err, "my transport error")
+
+	entries := buffer.Entries()
+	assert.Len(t, entries, 1)
+	assert.Equal(t, 0, entries[0].Response.Status)
+	assert.Equal(t, "my transport error", entries[0].Response.Error)
+	assert.Nil(t, entries[0].Request.PostData)
+	assert.Equal(t, "", entries[0].Response.Content.Text)
+}
+
+type errorSink struct{}
+
+func (errorSink) Add(har.Entry) error {
+	return errors.New("my sink error")
+}
+
+func TestHAR_SinkError(t *testing.T) {
+	myLogger, store := testing_logger.NewLogger()
+	transport := tripperware.HAR(myLogger, errorSink{})(loggerhttptest.ErrorTransport(errors.New("my transport error")))
+
+	_, err := transport.RoundTrip(httptest.NewRequest(http.MethodGet, "http://127.0.0.1/my-fake-url", nil))
+	assert.EqualError(t, err, "my transport error")
+
+	entries := store.GetEntries()
+	assert.Len(t, entries, 1)
+	assert.Equal(t, logger.WarningLevel, entries[0].Level)
+	assert.Equal(t, "har entry not recorded", entries[0].Message)
+	assert.EqualError(t, (*entries[0].Context)["har_error"].Value.(error), "my sink error")
+}
+
+func TestHAR_WithRequestFilter(t *testing.T) {
+	buffer := har.NewBuffer(0)
+	transport := tripperware.HAR(logger.NewNopLogger(), buffer, logger_http.WithRequestFilter(logger_http.PathFilter("/health")))(loggerhttptest.ResponseTransport(http.StatusOK, "OK", nil))
+
+	_, err := transport.RoundTrip(httptest.NewRequest(http.MethodGet, "http://127.0.0.1/health", nil))
+	assert.Nil(t, err)
+	assert.Len(t, buffer.Entries(), 0)
+}
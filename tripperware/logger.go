@@ -1,9 +1,8 @@
 package tripperware
 
 import (
-	"fmt"
+	"context"
 	"net/http"
-	"time"
 
 	"github.com/gol4ng/httpware/v4"
 	"github.com/gol4ng/logger"
@@ -13,48 +12,161 @@ import (
 
 // Logger will decorate the http.Client to add support of gol4ng/logger
 func Logger(log logger.LoggerInterface, opts ...logger_http.Option) func(next http.RoundTripper) http.RoundTripper {
+	return newLogger(func(ctx context.Context) logger.LoggerInterface {
+		return logger.FromContext(ctx, log)
+	}, opts...)
+}
+
+func newLogger(loggerFromContext func(context.Context) logger.LoggerInterface, opts ...logger_http.Option) func(next http.RoundTripper) http.RoundTripper {
 	o := logger_http.EvaluateClientOpt(opts...)
 	return func(next http.RoundTripper) http.RoundTripper {
 		return httpware.RoundTripFunc(func(req *http.Request) (resp *http.Response, err error) {
-			startTime := time.Now()
+			policy := o.Policy(req)
+			if policy.RequestFilter != nil && policy.RequestFilter(req) {
+				return next.RoundTrip(req)
+			}
+			debugToken := policy.DebugToken(req)
+			if debugToken != "" {
+				policy = policy.DebugPolicy()
+			}
+			sampled := policy.Sampler == nil || policy.Sampler(req)
+
+			startTime := policy.Clock.Now()
 			ctx := req.Context()
+			exchange := policy.NewExchange("client", req, startTime)
 
-			currentLogger := logger.FromContext(ctx, log)
-			currentLoggerContext := logger_http.FeedContext(o.LoggerContextProvider(req), ctx, req, startTime).Add("http_kind", "client")
+			currentLogger := policy.Emitter.Logger(policy.RenameFields(loggerFromContext(ctx)))
+			// the logger context is only built once an entry is going to be logged
+			var currentLoggerContext *logger.Context
+			loggerContext := func() *logger.Context {
+				if currentLoggerContext != nil {
+					return currentLoggerContext
+				}
+				currentLoggerContext = logger_http.FeedContext(policy.LoggerContextProvider(req), ctx, req, startTime).
+					Add("http_method", exchange.Method).
+					Add("http_url", exchange.URL).
+					Add("http_kind", "client")
+				if debugToken != "" {
+					currentLoggerContext.Add(logger_http.DebugFieldName, true)
+				}
+				if policy.BodyCaptureLimit > 0 {
+					if body := logger_http.GetBodyPrefix(req.GetBody, policy.BodyCaptureLimit); body != nil {
+						currentLoggerContext.Add("http_request_body", policy.Sanitize(string(body)))
+					}
+				}
+				return currentLoggerContext
+			}
+			defer func() {
+				logger_http.ReleaseContext(currentLoggerContext)
+			}()
+
+			dump := policy.DumpEnabled(req) && policy.Enabled(currentLogger, policy.DumpLevel)
 
 			defer func() {
-				duration := time.Since(startTime)
-				currentLoggerContext.Add("http_duration", duration.Seconds())
+				duration := policy.Clock.Since(startTime)
+				exchange.Duration = duration
+				endTime := policy.Clock.Now()
+				endLoggerContext := func() *logger.Context {
+					endContext := loggerContext().Add("http_duration", duration.Seconds())
+					logger_http.FeedBudgetContext(endContext, ctx, endTime)
+					return endContext
+				}
 
 				if err := recover(); err != nil {
-					currentLoggerContext.Add("http_panic", err)
-					currentLogger.Critical(fmt.Sprintf("http client panic %s %s [duration:%s]", req.Method, req.URL, duration), *currentLoggerContext.Slice()...)
+					exchange.Panic = err
+					policy.Observers.OnPanic(exchange)
+					currentLogger.Critical("http client panic "+exchange.Method+" "+exchange.URL+" [duration:"+duration.String()+"]", *endLoggerContext().Add("http_panic", err).Slice()...)
 					panic(err)
 				}
-				if err != nil {
-					currentLoggerContext.Add("http_error", err)
-					currentLoggerContext.Add("http_error_message", err.Error())
+				exchange.Error = err
+				exchange.Response = resp
+				if resp != nil {
+					exchange.StatusCode = resp.StatusCode
+					exchange.Status = resp.Status
+					exchange.ResponseLength = resp.ContentLength
 				}
+				policy.Observers.OnEnd(exchange)
+
+				if dump && resp != nil {
+					var body []byte
+					if policy.DumpBodyLimit > 0 && resp.Body != nil {
+						body, resp.Body = logger_http.PeekBody(resp.Body, policy.DumpBodyLimit)
+					}
+					currentLogger.Log("http client response dump "+exchange.Method+" "+exchange.URL, policy.DumpLevel, append(*endLoggerContext().Slice(), logger.String("http_dump", policy.DumpResponse(resp, body)))...)
+				}
+
 				if resp == nil {
-					currentLogger.Error(fmt.Sprintf("http client error %s %s [duration:%s] %s", req.Method, req.URL, duration, err), *currentLoggerContext.Slice()...)
+					if !policy.Enabled(currentLogger, logger.ErrorLevel) {
+						return
+					}
+					errorContext := endLoggerContext()
+					errorMessage := ""
+					if err != nil {
+						errorMessage = policy.Sanitize(err.Error())
+						errorContext.Add("http_error", sanitizedError{error: err, message: errorMessage}).Add("http_error_message", errorMessage)
+					}
+					if policy.ShouldCurl(0) {
+						errorContext.Add("http_curl", policy.CurlCommand(req))
+					}
+					currentLogger.Error("http client error "+exchange.Method+" "+exchange.URL+" [duration:"+duration.String()+"] "+errorMessage, *errorContext.Slice()...)
 					return
 				}
-				currentLoggerContext.Add("http_status", resp.Status).
+				level := policy.LevelFunc(resp.StatusCode)
+				if !sampled && level > logger.WarningLevel || !policy.Enabled(currentLogger, level) {
+					return
+				}
+
+				finalContext := endLoggerContext().
+					Add("http_status", resp.Status).
 					Add("http_status_code", resp.StatusCode).
 					Add("http_response_length", resp.ContentLength)
+				if err != nil {
+					errorMessage := policy.Sanitize(err.Error())
+					finalContext.Add("http_error", sanitizedError{error: err, message: errorMessage}).Add("http_error_message", errorMessage)
+				}
+
+				if policy.ShouldCurl(resp.StatusCode) {
+					finalContext.Add("http_curl", policy.CurlCommand(req))
+				}
+
+				if policy.BodyCaptureLimit > 0 && resp.Body != nil {
+					var body []byte
+					body, resp.Body = logger_http.PeekBody(resp.Body, policy.BodyCaptureLimit)
+					finalContext.Add("http_response_body", policy.Sanitize(string(body)))
+				}
 
-				currentLogger.Log(
-					fmt.Sprintf(
-						"http client %s %s [status_code:%d, duration:%s, content_length:%d]",
-						req.Method, req.URL, resp.StatusCode, duration, resp.ContentLength,
-					),
-					o.LevelFunc(resp.StatusCode),
-					*currentLoggerContext.Slice()...,
-				)
+				currentLogger.Log(exchange.Message(), level, *finalContext.Slice()...)
 			}()
 
-			currentLogger.Debug(fmt.Sprintf("http client gonna %s %s", req.Method, req.URL), *currentLoggerContext.Slice()...)
+			policy.Observers.OnStart(exchange)
+			if sampled && policy.Enabled(currentLogger, logger.DebugLevel) {
+				currentLogger.Debug("http client gonna "+exchange.Method+" "+exchange.URL, *loggerContext().Slice()...)
+			}
+			if dump {
+				requestDump := policy.DumpRequest(req, logger_http.GetBodyPrefix(req.GetBody, policy.DumpBodyLimit))
+				currentLogger.Log("http client request dump "+exchange.Method+" "+exchange.URL, policy.DumpLevel, append(*loggerContext().Slice(), logger.String("http_dump", requestDump))...)
+			}
+			if debugToken != "" && req.Header.Get(policy.DebugHeader) == "" && policy.ShouldForwardDebugToken(req.URL) {
+				// forward the debug token so the whole call chain gets verbose
+				forwarded := req.Clone(ctx)
+				forwarded.Header.Set(policy.DebugHeader, debugToken)
+				return next.RoundTrip(forwarded)
+			}
 			return next.RoundTrip(req)
 		})
 	}
 }
+
+// sanitizedError keeps the http_error field an error while its message is escaped like http_error_message
+type sanitizedError struct {
+	error
+	message string
+}
+
+func (e sanitizedError) Error() string {
+	return e.message
+}
+
+func (e sanitizedError) Unwrap() error {
+	return e.error
+}
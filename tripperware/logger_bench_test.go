@@ -0,0 +1,50 @@
+package tripperware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gol4ng/httpware/v4"
+	"github.com/gol4ng/logger"
+	logger_middleware "github.com/gol4ng/logger/middleware"
+
+	"github.com/gol4ng/logger-http"
+	"github.com/gol4ng/logger-http/tripperware"
+)
+
+func benchmarkTripperware(b *testing.B, l logger.LoggerInterface, opts ...logger_http.Option) {
+	response := &http.Response{Status: "204 No Content", StatusCode: http.StatusNoContent}
+	transport := tripperware.Logger(l, opts...)(httpware.RoundTripFunc(func(req *http.Request) (*http.Response, error) {
+		return response, nil
+	}))
+	request := httptest.NewRequest(http.MethodGet, "http://127.0.0.1/my-fake-url", nil)
+	request.Header.Set("User-Agent", "benchmark")
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		transport.RoundTrip(request)
+	}
+}
+
+func BenchmarkTripperware(b *testing.B) {
+	benchmarkTripperware(b, logger.NewNopLogger())
+}
+
+func BenchmarkTripperware_WithMinLevel(b *testing.B) {
+	benchmarkTripperware(b, logger.NewNopLogger(), logger_http.WithMinLevel(logger.InfoLevel))
+}
+
+func BenchmarkTripperware_Filtered(b *testing.B) {
+	benchmarkTripperware(b, logger.NewNopLogger(), logger_http.WithMinLevel(logger.WarningLevel))
+}
+
+// the decorators cannot see the gol4ng handler filter, every entry is built then dropped
+func BenchmarkTripperware_MinLevelFilter(b *testing.B) {
+	benchmarkTripperware(b, logger.NewLogger(logger_middleware.MinLevelFilter(logger.WarningLevel)(logger.NopHandler)))
+}
+
+func BenchmarkTripperware_MinLevelFilterWithMinLevel(b *testing.B) {
+	benchmarkTripperware(b, logger.NewLogger(logger_middleware.MinLevelFilter(logger.WarningLevel)(logger.NopHandler)), logger_http.WithMinLevel(logger.WarningLevel))
+}
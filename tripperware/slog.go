@@ -0,0 +1,64 @@
+//go:build go1.21
+// +build go1.21
+
+package tripperware
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+
+	"github.com/gol4ng/httpware/v4"
+	"github.com/gol4ng/httpware/v4/correlation_id"
+	http_tripperware "github.com/gol4ng/httpware/v4/tripperware"
+	"github.com/gol4ng/logger"
+
+	logger_http "github.com/gol4ng/logger-http"
+)
+
+// SlogLogger will decorate the http.Client to log with the *slog.Logger from the request context or the given one
+// it emits the same messages and fields as Logger
+func SlogLogger(log *slog.Logger, opts ...logger_http.Option) func(next http.RoundTripper) http.RoundTripper {
+	return newLogger(func(ctx context.Context) logger.LoggerInterface {
+		return logger_http.NewSlogBridge(ctx, logger_http.SlogFromContext(ctx, log))
+	}, opts...)
+}
+
+// SlogInjectLogger will inject the *slog.Logger on request context if not exist
+func SlogInjectLogger(log *slog.Logger) httpware.Tripperware {
+	return func(next http.RoundTripper) http.RoundTripper {
+		return httpware.RoundTripFunc(func(req *http.Request) (resp *http.Response, err error) {
+			ctx := req.Context()
+			if logger_http.SlogFromContext(ctx, nil) == nil {
+				req = req.WithContext(logger_http.InjectSlogInContext(ctx, log))
+			}
+			return next.RoundTrip(req)
+		})
+	}
+}
+
+// SlogCorrelationId is the slog equivalent of CorrelationId
+// it will add correlationId to the *slog.Logger of the request context
+// eg:
+//
+//	stack := httpware.TripperwareStack(
+//		tripperware.SlogInjectLogger(l), // << Inject logger before SlogCorrelationId
+//		tripperware.SlogCorrelationId(),
+//	)
+func SlogCorrelationId(options ...correlation_id.Option) httpware.Tripperware {
+	warning := logger_http.MessageWithFileLine("correlationId need a slog logger", 1)
+	config := correlation_id.GetConfig(options...)
+	orig := http_tripperware.CorrelationId(options...)
+	return func(next http.RoundTripper) http.RoundTripper {
+		return orig(httpware.RoundTripFunc(func(req *http.Request) (resp *http.Response, err error) {
+			ctx := req.Context()
+			if requestLogger := logger_http.SlogFromContext(ctx, nil); requestLogger != nil {
+				req = req.WithContext(logger_http.InjectSlogInContext(ctx, requestLogger.With(config.HeaderName, ctx.Value(config.HeaderName))))
+			} else {
+				fmt.Println(warning)
+			}
+			return next.RoundTrip(req)
+		}))
+	}
+}
@@ -0,0 +1,73 @@
+//go:build go1.21
+// +build go1.21
+
+package tripperware_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gol4ng/httpware/v4"
+	"github.com/gol4ng/httpware/v4/correlation_id"
+	"github.com/stretchr/testify/assert"
+
+	logger_http "github.com/gol4ng/logger-http"
+	"github.com/gol4ng/logger-http/tripperware"
+)
+
+func TestSlogLogger(t *testing.T) {
+	transport := httpware.RoundTripFunc(func(req *http.Request) (*http.Response, error) {
+		return &http.Response{Status: "200 OK", StatusCode: http.StatusOK, ContentLength: 2}, nil
+	})
+
+	buffer := &bytes.Buffer{}
+	myLogger := slog.New(slog.NewJSONHandler(buffer, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+	_, err := tripperware.SlogLogger(myLogger)(transport).RoundTrip(httptest.NewRequest(http.MethodGet, "http://127.0.0.1/my-fake-url", nil))
+	assert.Nil(t, err)
+
+	lines := strings.Split(strings.TrimSpace(buffer.String()), "\n")
+	assert.Len(t, lines, 2)
+
+	entry := map[string]interface{}{}
+	assert.Nil(t, json.Unmarshal([]byte(lines[1]), &entry))
+	assert.Equal(t, "INFO", entry["level"])
+	assert.Contains(t, entry["msg"], "http client GET http://127.0.0.1/my-fake-url [status_code:200, duration:")
+	assert.Equal(t, "client", entry["http_kind"])
+	assert.Equal(t, "GET", entry["http_method"])
+	assert.Equal(t, "http://127.0.0.1/my-fake-url", entry["http_url"])
+	assert.Equal(t, float64(200), entry["http_status_code"])
+	assert.Contains(t, entry, "http_duration")
+}
+
+func TestSlogCorrelationId(t *testing.T) {
+	correlation_id.DefaultIdGenerator = correlation_id.NewRandomIdGenerator(
+		rand.New(correlation_id.NewLockedSource(rand.NewSource(1))),
+	)
+
+	buffer := &bytes.Buffer{}
+	myLogger := slog.New(slog.NewJSONHandler(buffer, nil))
+
+	transport := httpware.RoundTripFunc(func(req *http.Request) (*http.Response, error) {
+		logger_http.SlogFromContext(req.Context(), nil).Info("transport info log")
+		return &http.Response{Status: "200 OK", StatusCode: http.StatusOK}, nil
+	})
+
+	stack := httpware.TripperwareStack(
+		tripperware.SlogInjectLogger(myLogger),
+		tripperware.SlogCorrelationId(),
+	)
+	_, err := stack.DecorateRoundTripper(transport).RoundTrip(httptest.NewRequest(http.MethodGet, "http://fake-addr", nil))
+	assert.Nil(t, err)
+
+	entry := map[string]interface{}{}
+	assert.Nil(t, json.Unmarshal(buffer.Bytes(), &entry))
+	assert.Equal(t, "transport info log", entry["msg"])
+	assert.Equal(t, "p1LGIehp1s", entry["Correlation-Id"])
+}
@@ -0,0 +1,122 @@
+package logger_http
+
+import (
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// WithRedactedQueryParams will redact the given query parameter values in the logged url
+func WithRedactedQueryParams(names ...string) Option {
+	return func(o *Options) {
+		o.RedactedQueryParams = append(append([]string{}, o.RedactedQueryParams...), names...)
+	}
+}
+
+// WithRedactedPathSegments will redact every url path segment matching one of the given patterns
+// eg: WithRedactedPathSegments(regexp.MustCompile(`^[0-9a-f]{32}$`))
+func WithRedactedPathSegments(patterns ...*regexp.Regexp) Option {
+	return func(o *Options) {
+		o.RedactedPathSegments = append(append([]*regexp.Regexp{}, o.RedactedPathSegments...), patterns...)
+	}
+}
+
+// WithStripUserinfo will remove the user and password from the logged url
+func WithStripUserinfo() Option {
+	return func(o *Options) {
+		o.StripUserinfo = true
+	}
+}
+
+// SanitizeURL will return the url string with the configured redaction rules applied
+func (o *Options) SanitizeURL(u *url.URL) string {
+	if u == nil {
+		return ""
+	}
+	if !o.StripUserinfo && len(o.RedactedQueryParams) == 0 && len(o.RedactedPathSegments) == 0 {
+		return u.String()
+	}
+
+	base := *u
+	if o.StripUserinfo {
+		base.User = nil
+	}
+	if base.Opaque != "" {
+		base.RawQuery = redactQuery(u.RawQuery, o.RedactedQueryParams)
+		return base.String()
+	}
+	base.Path, base.RawPath, base.RawQuery, base.ForceQuery, base.Fragment = "", "", "", false, ""
+
+	builder := strings.Builder{}
+	builder.WriteString(base.String())
+	path := redactPath(u.EscapedPath(), o.RedactedPathSegments)
+	if path != "" && path[0] != '/' && u.Host != "" {
+		builder.WriteByte('/')
+	}
+	builder.WriteString(path)
+	if u.ForceQuery || u.RawQuery != "" {
+		builder.WriteByte('?')
+		builder.WriteString(redactQuery(u.RawQuery, o.RedactedQueryParams))
+	}
+	if u.Fragment != "" {
+		builder.WriteString((&url.URL{Fragment: u.Fragment}).String())
+	}
+	return builder.String()
+}
+
+// IsRedactedQueryParam will return true when the query parameter value must be redacted
+func (o *Options) IsRedactedQueryParam(name string) bool {
+	for _, redacted := range o.RedactedQueryParams {
+		if strings.EqualFold(name, redacted) {
+			return true
+		}
+	}
+	return false
+}
+
+func redactQuery(rawQuery string, names []string) string {
+	if len(names) == 0 || rawQuery == "" {
+		return rawQuery
+	}
+	params := strings.Split(rawQuery, "&")
+	for i, param := range params {
+		rawKey := param
+		if index := strings.IndexByte(param, '='); index >= 0 {
+			rawKey = param[:index]
+		}
+		key, err := url.QueryUnescape(rawKey)
+		if err != nil {
+			key = rawKey
+		}
+		for _, name := range names {
+			if strings.EqualFold(key, name) {
+				params[i] = rawKey + "=" + RedactedValue
+				break
+			}
+		}
+	}
+	return strings.Join(params, "&")
+}
+
+func redactPath(escapedPath string, patterns []*regexp.Regexp) string {
+	if len(patterns) == 0 || escapedPath == "" {
+		return escapedPath
+	}
+	segments := strings.Split(escapedPath, "/")
+	for i, segment := range segments {
+		if segment == "" {
+			continue
+		}
+		unescaped, err := url.PathUnescape(segment)
+		if err != nil {
+			unescaped = segment
+		}
+		for _, pattern := range patterns {
+			if pattern.MatchString(unescaped) {
+				segments[i] = RedactedValue
+				break
+			}
+		}
+	}
+	return strings.Join(segments, "/")
+}
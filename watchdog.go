@@ -0,0 +1,224 @@
+package logger_http
+
+import (
+	"bytes"
+	"runtime"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gol4ng/logger"
+)
+
+// maxStackSize caps the buffer used to capture every goroutine stack
+const maxStackSize = 16 << 20
+
+// WithWatchdog will log a warning with the handler goroutine stack when a request runs past threshold
+// the warning is repeated every interval until the request ends, an interval lower or equal to 0 uses the threshold
+// a threshold lower or equal to 0 disables the watchdog
+// the watched requests share a single ticker of the Clock (see TickerClock) running a quarter of the shortest duration,
+// every tick dumps the goroutine stacks at most once for all the requests due
+func WithWatchdog(threshold time.Duration, interval time.Duration) Option {
+	return func(o *Options) {
+		o.WatchdogThreshold = threshold
+		o.WatchdogInterval = interval
+		o.watchdog = &watchdog{watched: map[*watchedExchange]struct{}{}}
+	}
+}
+
+// StartWatchdog will watch the exchange handled by the calling goroutine
+// loggerContext gives the request fields of the warnings (http_method, http_url, http_kind...), it is only called when a warning is logged,
+// from the watchdog goroutine: the calling goroutine must not use it until stop returns
+// without loggerContext the warnings hold the request fields of the exchange
+// the returned function stops the watchdog, it waits for a warning being logged so none is logged after it returns
+func (o *Options) StartWatchdog(l logger.LoggerInterface, exchange *Exchange, loggerContext func() *logger.Context) (stop func()) {
+	if o.WatchdogThreshold <= 0 || o.watchdog == nil || !o.Enabled(l, logger.WarningLevel) {
+		return func() {}
+	}
+	interval := o.WatchdogInterval
+	if interval <= 0 {
+		interval = o.WatchdogThreshold
+	}
+	watched := &watchedExchange{
+		logger:        l,
+		exchange:      exchange,
+		goroutineId:   GoroutineId(),
+		loggerContext: loggerContext,
+		interval:      interval,
+		nextWarning:   exchange.StartTime.Add(o.WatchdogThreshold),
+	}
+	tick := o.WatchdogThreshold
+	if interval < tick {
+		tick = interval
+	}
+	o.watchdog.add(watched, o.Clock, tick/4)
+	return func() {
+		watched.mutex.Lock()
+		watched.stopped = true
+		watched.mutex.Unlock()
+		o.watchdog.remove(watched)
+	}
+}
+
+// watchdog checks the exchanges watched with one Options on every tick of a shared ticker
+// the ticker only runs while exchanges are watched
+type watchdog struct {
+	mutex      sync.Mutex
+	watched    map[*watchedExchange]struct{}
+	stopTicker func()
+}
+
+type watchedExchange struct {
+	logger      logger.LoggerInterface
+	exchange    *Exchange
+	goroutineId uint64
+	// loggerContext is only called under mutex, see StartWatchdog
+	loggerContext func() *logger.Context
+	interval      time.Duration
+	// nextWarning is guarded by the watchdog mutex
+	nextWarning time.Time
+
+	mutex   sync.Mutex
+	stopped bool
+}
+
+func (w *watchdog) add(watched *watchedExchange, clock Clock, tick time.Duration) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	w.watched[watched] = struct{}{}
+	if w.stopTicker != nil {
+		return
+	}
+	if tick <= 0 {
+		tick = time.Millisecond
+	}
+	ticks, stopTicker := newTicker(clock, tick)
+	done := make(chan struct{})
+	w.stopTicker = func() {
+		stopTicker()
+		close(done)
+	}
+	go func() {
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticks:
+				w.check(clock)
+			}
+		}
+	}()
+}
+
+func (w *watchdog) remove(watched *watchedExchange) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	delete(w.watched, watched)
+	if len(w.watched) == 0 && w.stopTicker != nil {
+		w.stopTicker()
+		w.stopTicker = nil
+	}
+}
+
+func (w *watchdog) check(clock Clock) {
+	now := clock.Now()
+	w.mutex.Lock()
+	var due []*watchedExchange
+	for watched := range w.watched {
+		if !now.Before(watched.nextWarning) {
+			due = append(due, watched)
+			watched.nextWarning = now.Add(watched.interval)
+		}
+	}
+	w.mutex.Unlock()
+	if len(due) == 0 {
+		return
+	}
+
+	// a single stop-the-world dump serves every due exchange
+	stacks := allStacks()
+	for _, watched := range due {
+		watched.warn(now, goroutineStack(stacks, watched.goroutineId))
+	}
+}
+
+func (w *watchedExchange) warn(now time.Time, stack string) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	if w.stopped {
+		return
+	}
+	exchange := w.exchange
+	duration := now.Sub(exchange.StartTime)
+	loggerContext := w.requestContext()
+	fields := append(*loggerContext.Slice(),
+		logger.Float64("http_duration", duration.Seconds()),
+		logger.Uint64("http_goroutine", w.goroutineId),
+		logger.String("http_stack", SanitizeString(stack, 0)),
+	)
+	if _, ok := (*loggerContext)["http_correlation_id"]; !ok && exchange.CorrelationId != "" {
+		fields = append(fields, logger.String("http_correlation_id", exchange.CorrelationId))
+	}
+	w.logger.Warning("http "+exchange.Kind+" still running "+exchange.Method+" "+exchange.URL+" [duration:"+duration.String()+"]", fields...)
+}
+
+func (w *watchedExchange) requestContext() *logger.Context {
+	if w.loggerContext != nil {
+		return w.loggerContext()
+	}
+	return logger.NewContext().
+		Add("http_method", w.exchange.Method).
+		Add("http_url", w.exchange.URL).
+		Add("http_kind", w.exchange.Kind).
+		Add("http_start_time", w.exchange.StartTime.Format(time.RFC3339))
+}
+
+// GoroutineId will return the id of the calling goroutine as printed in its stack trace
+func GoroutineId() uint64 {
+	buffer := make([]byte, 64)
+	buffer = buffer[:runtime.Stack(buffer, false)]
+	// "goroutine 18 [running]:"
+	buffer = bytes.TrimPrefix(buffer, []byte("goroutine "))
+	if index := bytes.IndexByte(buffer, ' '); index >= 0 {
+		buffer = buffer[:index]
+	}
+	id, _ := strconv.ParseUint(string(buffer), 10, 64)
+	return id
+}
+
+// GoroutineStack will return the current stack of the goroutine with the given id
+// an empty string is returned when the goroutine does not exist anymore
+func GoroutineStack(id uint64) string {
+	return goroutineStack(allStacks(), id)
+}
+
+func goroutineStack(stacks []byte, id uint64) string {
+	header := []byte("goroutine " + strconv.FormatUint(id, 10) + " [")
+	start := bytes.Index(stacks, header)
+	for start > 0 && stacks[start-1] != '\n' {
+		next := bytes.Index(stacks[start+1:], header)
+		if next < 0 {
+			return ""
+		}
+		start += next + 1
+	}
+	if start < 0 {
+		return ""
+	}
+	stack := stacks[start:]
+	if end := bytes.Index(stack, []byte("\n\n")); end >= 0 {
+		stack = stack[:end]
+	}
+	return string(bytes.TrimRight(stack, "\n"))
+}
+
+func allStacks() []byte {
+	buffer := make([]byte, 64<<10)
+	for {
+		n := runtime.Stack(buffer, true)
+		if n < len(buffer) || len(buffer) >= maxStackSize {
+			return buffer[:n]
+		}
+		buffer = make([]byte, 2*len(buffer))
+	}
+}
@@ -0,0 +1,150 @@
+package logger_http_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gol4ng/logger"
+	testing_logger "github.com/gol4ng/logger/testing"
+	"github.com/stretchr/testify/assert"
+
+	logger_http "github.com/gol4ng/logger-http"
+	"github.com/gol4ng/logger-http/loggerhttptest"
+)
+
+func TestWithWatchdog(t *testing.T) {
+	o := logger_http.EvaluateServerOpt(logger_http.WithWatchdog(time.Second, 5*time.Second))
+	assert.Equal(t, time.Second, o.WatchdogThreshold)
+	assert.Equal(t, 5*time.Second, o.WatchdogInterval)
+}
+
+func TestGoroutineId(t *testing.T) {
+	id := logger_http.GoroutineId()
+	assert.NotZero(t, id)
+	assert.Equal(t, id, logger_http.GoroutineId())
+
+	otherId := make(chan uint64)
+	go func() {
+		otherId <- logger_http.GoroutineId()
+	}()
+	assert.NotEqual(t, id, <-otherId)
+}
+
+func blockedGoroutine(started chan<- uint64, release <-chan struct{}) {
+	started <- logger_http.GoroutineId()
+	<-release
+}
+
+func TestGoroutineStack(t *testing.T) {
+	started := make(chan uint64)
+	release := make(chan struct{})
+	go blockedGoroutine(started, release)
+	id := <-started
+
+	stack := logger_http.GoroutineStack(id)
+	assert.True(t, strings.HasPrefix(stack, "goroutine "), stack)
+	assert.Contains(t, stack, "blockedGoroutine")
+	assert.NotContains(t, stack, "TestGoroutineStack(")
+	close(release)
+
+	assert.Equal(t, "", logger_http.GoroutineStack(0))
+}
+
+func TestOptions_StartWatchdog(t *testing.T) {
+	myLogger, store := testing_logger.NewLogger()
+	clock := loggerhttptest.NewClock(time.Date(2019, 12, 13, 17, 1, 13, 0, time.UTC))
+	o := logger_http.EvaluateServerOpt(logger_http.WithClock(clock), logger_http.WithWatchdog(10*time.Second, 5*time.Second))
+	exchange := &logger_http.Exchange{Kind: "server", Method: "GET", URL: "http://127.0.0.1/my-fake-url", StartTime: clock.Now(), CorrelationId: "my-correlation-id"}
+
+	calls := 0
+	stop := o.StartWatchdog(myLogger, exchange, func() *logger.Context {
+		calls++
+		return logger.NewContext().Add("my_key", "my_value")
+	})
+	clock.Add(9 * time.Second)
+	clock.Add(time.Second)
+	assert.Eventually(t, func() bool { return len(store.GetEntries()) == 1 }, time.Second, time.Millisecond)
+	clock.Add(time.Second)
+	clock.Add(4 * time.Second)
+	assert.Eventually(t, func() bool { return len(store.GetEntries()) == 2 }, time.Second, time.Millisecond)
+	stop()
+	clock.Add(time.Hour)
+	// the logger context is only built for the warnings
+	assert.Equal(t, 2, calls)
+
+	entries := store.GetEntries()
+	assert.Len(t, entries, 2)
+	entry := entries[0]
+	assert.Equal(t, logger.WarningLevel, entry.Level)
+	assert.Equal(t, "http server still running GET http://127.0.0.1/my-fake-url [duration:10s]", entry.Message)
+	assert.Equal(t, logger_http.GoroutineId(), (*entry.Context)["http_goroutine"].Value)
+	assert.Contains(t, (*entry.Context)["http_stack"].Value, "TestOptions_StartWatchdog")
+	assert.NotContains(t, (*entry.Context)["http_stack"].Value, "\n")
+	assert.Equal(t, 10.0, (*entry.Context)["http_duration"].Value)
+	assert.Equal(t, "my-correlation-id", (*entry.Context)["http_correlation_id"].Value)
+	assert.Equal(t, "my_value", (*entry.Context)["my_key"].Value)
+	assert.Equal(t, "http server still running GET http://127.0.0.1/my-fake-url [duration:15s]", entries[1].Message)
+}
+
+func TestOptions_StartWatchdog_SharedTicker(t *testing.T) {
+	myLogger, store := testing_logger.NewLogger()
+	clock := loggerhttptest.NewClock(time.Date(2019, 12, 13, 17, 1, 13, 0, time.UTC))
+	o := logger_http.EvaluateServerOpt(logger_http.WithClock(clock), logger_http.WithWatchdog(10*time.Second, 0))
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	done := make(chan struct{})
+	for _, url := range []string{"http://127.0.0.1/first", "http://127.0.0.1/second"} {
+		exchange := &logger_http.Exchange{Kind: "server", Method: "GET", URL: url, StartTime: clock.Now()}
+		go func() {
+			defer func() { done <- struct{}{} }()
+			defer o.StartWatchdog(myLogger, exchange, nil)()
+			started <- struct{}{}
+			<-release
+		}()
+	}
+	<-started
+	<-started
+	clock.Add(10 * time.Second)
+	assert.Eventually(t, func() bool { return len(store.GetEntries()) == 2 }, time.Second, time.Millisecond)
+	close(release)
+	<-done
+	<-done
+	clock.Add(time.Hour)
+
+	entries := store.GetEntries()
+	assert.Len(t, entries, 2)
+	assert.NotEqual(t, (*entries[0].Context)["http_goroutine"].Value, (*entries[1].Context)["http_goroutine"].Value)
+	assert.NotContains(t, *entries[0].Context, "http_correlation_id")
+	// without logger context the warnings hold the request fields of the exchange
+	assert.Equal(t, "server", (*entries[0].Context)["http_kind"].Value)
+	assert.Equal(t, "GET", (*entries[0].Context)["http_method"].Value)
+}
+
+func TestOptions_StartWatchdog_Disabled(t *testing.T) {
+	myLogger, store := testing_logger.NewLogger()
+	clock := loggerhttptest.NewClock(time.Date(2019, 12, 13, 17, 1, 13, 0, time.UTC))
+	exchange := &logger_http.Exchange{StartTime: clock.Now()}
+
+	logger_http.EvaluateServerOpt(logger_http.WithClock(clock)).StartWatchdog(myLogger, exchange, nil)()
+	stop := logger_http.EvaluateServerOpt(
+		logger_http.WithClock(clock),
+		logger_http.WithWatchdog(time.Second, 0),
+		logger_http.WithMinLevel(logger.ErrorLevel),
+	).StartWatchdog(myLogger, exchange, nil)
+	clock.Add(time.Hour)
+	stop()
+
+	stop = logger_http.EvaluateServerOpt(
+		logger_http.WithClock(clock),
+		logger_http.WithWatchdog(time.Hour, 0),
+	).StartWatchdog(myLogger, &logger_http.Exchange{StartTime: clock.Now()}, func() *logger.Context {
+		t.Error("the logger context must not be built before a warning")
+		return logger.NewContext()
+	})
+	clock.Add(time.Minute)
+	stop()
+
+	assert.Empty(t, store.GetEntries())
+}